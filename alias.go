@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+// Alias is a short name for an issue, usable as a tag in timewarrior.
+type Alias struct {
+	Issue    string `json:"issue"`
+	Activity string `json:"activity,omitempty"`
+}
+
+type AliasStore struct {
+	path    string
+	Aliases map[string]Alias
+}
+
+func loadAliases() (*AliasStore, error) {
+	path, err := xdg.ConfigFile("worklogger/aliases.json")
+	if err != nil {
+		return nil, err
+	}
+
+	store := &AliasStore{
+		path:    path,
+		Aliases: map[string]Alias{},
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &store.Aliases); err != nil {
+		return nil, fmt.Errorf("error reading %s: %s", path, err)
+	}
+
+	return store, nil
+}
+
+// aliasMap loads the aliases for the commands resolving them, so a broken
+// aliases file only fails those.
+func aliasMap() (map[string]Alias, error) {
+	store, err := loadAliases()
+	if err != nil {
+		return nil, err
+	}
+	return store.Aliases, nil
+}
+
+// loadAliases sets the aliases resolved when parsing the intervals.
+func (el *EntryList) loadAliases() error {
+	aliases, err := aliasMap()
+	if err != nil {
+		return err
+	}
+	el.Aliases = aliases
+	return nil
+}
+
+func (s *AliasStore) save() error {
+	data, err := json.MarshalIndent(s.Aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0o644)
+}
+
+// normalizeIssue accepts the issue notations of tags and trackers, e.g.
+// `R_123`, `#123` or `123` for Redmine and `J_45` or `PIM-45` for JIRA.
+// Issues of other JIRA projects are tagged with their key, e.g. `J_OPS-45`.
+func normalizeIssue(issue string) (string, error) {
+	switch {
+	case regexp.MustCompile(`^(R_|#)?\d+$`).MatchString(issue):
+		return "#" + strings.TrimLeft(issue, "R_#"), nil
+	case regexp.MustCompile(`^J_\d+$`).MatchString(issue):
+		return "PIM-" + strings.TrimPrefix(issue, "J_"), nil
+	case strings.HasPrefix(issue, "J_") && jiraKeyRexp.MatchString(strings.TrimPrefix(issue, "J_")):
+		return strings.TrimPrefix(issue, "J_"), nil
+	case jiraKeyRexp.MatchString(issue):
+		return issue, nil
+	}
+
+	return "", fmt.Errorf("invalid issue %q, please use e.g. '#123', 'R_123', 'PIM-45' or 'J_45'", issue)
+}
+
+// validateIssue checks that the issue exists and, for Redmine, that the
+// activity is enabled in its project.
+func validateIssue(resolver *IssueResolver, issueID, activityID string) (IssueInfo, error) {
+	info, err := resolver.Resolve(issueID)
+	if err != nil {
+		return info, err
+	}
+
+	// the activities are not cached, so they can only be checked online
+	if activityID == "" || !strings.HasPrefix(issueID, "#") || resolver.Offline || resolver.Redmine == nil {
+		return info, nil
+	}
+
+	activities, err := resolver.Redmine.getActivities(info.ProjectID)
+	if err != nil {
+		return info, err
+	}
+	for _, activity := range activities {
+		if activity.ID == activityID {
+			return info, nil
+		}
+	}
+
+	return info, fmt.Errorf("activity %s is not available in project %s", activityID, info.Project)
+}
+
+func aliasCommand() cli.Command {
+	return cli.Command{
+		Name:  "alias",
+		Usage: "Manage short names for issues which can be used as tags in timewarrior.",
+		Subcommands: []cli.Command{
+			{
+				Name:      "add",
+				Usage:     "Add or replace an alias after validating the issue.",
+				ArgsUsage: "<alias> <issue>",
+				Flags: append(append([]cli.Flag{
+					&cli.StringFlag{
+						Name:  "activity",
+						Usage: "The Redmine activity ID used for entries with the alias.",
+					},
+				}, redmineFlags()...), jiraFlags()...),
+				Action: func(ctx *cli.Context) error {
+					if ctx.NArg() != 2 {
+						return fmt.Errorf("please give an alias and an issue")
+					}
+
+					name := ctx.Args().Get(0)
+					if !regexp.MustCompile(`^[\w.-]+$`).MatchString(name) || regexp.MustCompile(`^[RJA]_`).MatchString(name) {
+						return fmt.Errorf("invalid alias %q, use a single word which does not start with R_, J_ or A_", name)
+					}
+
+					issueID, err := normalizeIssue(ctx.Args().Get(1))
+					if err != nil {
+						return err
+					}
+
+					resolver, err := newIssueResolver(ctx)
+					if err != nil {
+						return err
+					}
+					info, err := validateIssue(resolver, issueID, ctx.String("activity"))
+					if err != nil {
+						return err
+					}
+					if err := resolver.Save(); err != nil {
+						return err
+					}
+
+					store, err := loadAliases()
+					if err != nil {
+						return err
+					}
+					store.Aliases[name] = Alias{Issue: issueID, Activity: ctx.String("activity")}
+					if err := store.save(); err != nil {
+						return err
+					}
+
+					fmt.Printf("%s -> %s (%s)\n", name, issueID, info.Subject)
+					return nil
+				},
+			},
+			{
+				Name:      "rm",
+				Usage:     "Remove an alias.",
+				ArgsUsage: "<alias>",
+				Action: func(ctx *cli.Context) error {
+					store, err := loadAliases()
+					if err != nil {
+						return err
+					}
+
+					name := ctx.Args().First()
+					if _, ok := store.Aliases[name]; !ok {
+						return fmt.Errorf("unknown alias %q", name)
+					}
+					delete(store.Aliases, name)
+
+					return store.save()
+				},
+			},
+			{
+				Name:  "ls",
+				Usage: "List the aliases.",
+				Action: func(ctx *cli.Context) error {
+					store, err := loadAliases()
+					if err != nil {
+						return err
+					}
+
+					names := []string{}
+					for name := range store.Aliases {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+
+					table := tablewriter.NewWriter(os.Stdout)
+					table.SetHeader([]string{"Alias", "Issue", "Activity"})
+					for _, name := range names {
+						alias := store.Aliases[name]
+						table.Append([]string{name, alias.Issue, alias.Activity})
+					}
+					table.Render()
+
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+var weekdays = map[string]time.Weekday{
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+	"sun": time.Sunday,
+}
+
+// Schedule defines the contractual hours per weekday, e.g. `{"mon": 8}`, which
+// are valid between From and To (both inclusive, To may be empty).
+type Schedule struct {
+	From  string             `json:"from"`
+	To    string             `json:"to"`
+	Hours map[string]float64 `json:"hours"`
+}
+
+type BalanceConfig struct {
+	// Start is the date from which the overtime balance is accumulated.
+	Start     string     `json:"start"`
+	Schedules []Schedule `json:"schedules"`
+	// AbsenceTags mark intervals like vacation or sick days. Days with such an
+	// interval have no target hours and the interval itself is not counted.
+	AbsenceTags []string `json:"absenceTags"`
+}
+
+func (s Schedule) validFor(day time.Time) (bool, error) {
+	date := day.Format("2006-01-02")
+	if s.From != "" {
+		if _, err := time.Parse("2006-01-02", s.From); err != nil {
+			return false, fmt.Errorf("invalid schedule start %q: %s", s.From, err)
+		}
+		if date < s.From {
+			return false, nil
+		}
+	}
+	if s.To != "" {
+		if _, err := time.Parse("2006-01-02", s.To); err != nil {
+			return false, fmt.Errorf("invalid schedule end %q: %s", s.To, err)
+		}
+		if date > s.To {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// WorkCalendar answers how many hours are expected on a given day.
+type WorkCalendar struct {
+	Schedules []Schedule
+	Holidays  *HolidayCalendar
+}
+
+func newWorkCalendar(config *Config) (*WorkCalendar, error) {
+	for _, schedule := range config.Balance.Schedules {
+		for day := range schedule.Hours {
+			if _, ok := weekdays[day]; !ok {
+				return nil, fmt.Errorf("invalid weekday %q in schedule, please use 'mon' to 'sun'", day)
+			}
+		}
+	}
+
+	holidays, err := newHolidayCalendar(config.Holidays)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WorkCalendar{
+		Schedules: config.Balance.Schedules,
+		Holidays:  holidays,
+	}, nil
+}
+
+func (wc *WorkCalendar) Target(day time.Time) (float64, error) {
+	if _, ok := wc.Holidays.Holiday(day); ok {
+		return 0, nil
+	}
+
+	for _, schedule := range wc.Schedules {
+		ok, err := schedule.validFor(day)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			continue
+		}
+
+		for name, hours := range schedule.Hours {
+			if weekdays[name] == day.Weekday() {
+				return hours, nil
+			}
+		}
+		return 0, nil
+	}
+
+	return 0, nil
+}
+
+type BalanceRow struct {
+	Period  string
+	Target  float64
+	Actual  float64
+	Absent  int
+	Balance float64
+}
+
+// balance compares the tracked hours against the calendar for every day
+// between from and to (exclusive) and groups the result per day, week or
+// month. The balance column is the running total of the differences.
+func (el *EntryList) balance(wc *WorkCalendar, absenceTags []string, from, to time.Time, per string) ([]BalanceRow, error) {
+	var period func(time.Time) string
+	switch per {
+	case "day":
+		period = func(day time.Time) string {
+			return day.Format("2006-01-02 Mon")
+		}
+	case "week":
+		period = func(day time.Time) string {
+			year, week := day.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}
+	case "month":
+		period = func(day time.Time) string {
+			return day.Format("2006-01")
+		}
+	default:
+		return nil, fmt.Errorf("invalid period %q, please use 'day', 'week' or 'month'", per)
+	}
+
+	loc := from.Location()
+	actual := map[string]float64{}
+	absent := map[string]bool{}
+	for _, entry := range el.Entries {
+		date := entry.Start.In(loc).Format("2006-01-02")
+		if entry.hasAnyTag(absenceTags) {
+			absent[date] = true
+			continue
+		}
+		actual[date] += entry.Hours.Hours()
+	}
+
+	rows := []BalanceRow{}
+	balance := 0.0
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+
+		target, err := wc.Target(day)
+		if err != nil {
+			return nil, err
+		}
+		if absent[date] {
+			target = 0
+		}
+
+		balance += actual[date] - target
+
+		p := period(day)
+		if len(rows) == 0 || rows[len(rows)-1].Period != p {
+			rows = append(rows, BalanceRow{Period: p})
+		}
+		row := &rows[len(rows)-1]
+		row.Target += target
+		row.Actual += actual[date]
+		row.Balance = balance
+		if absent[date] {
+			row.Absent++
+		}
+	}
+
+	return rows, nil
+}
+
+func signedHours(hours float64) string {
+	return fmt.Sprintf("%+.2f", hours)
+}
+
+func writeBalance(w io.Writer, rows []BalanceRow) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Period", "Target", "Actual", "Difference", "Absent days", "Balance"})
+
+	target, actual := 0.0, 0.0
+	for _, row := range rows {
+		target += row.Target
+		actual += row.Actual
+		table.Append([]string{
+			row.Period,
+			fmt.Sprintf("%.2f", row.Target),
+			fmt.Sprintf("%.2f", row.Actual),
+			signedHours(row.Actual - row.Target),
+			fmt.Sprintf("%d", row.Absent),
+			signedHours(row.Balance),
+		})
+	}
+
+	balance := 0.0
+	if len(rows) > 0 {
+		balance = rows[len(rows)-1].Balance
+	}
+	table.SetFooter([]string{"Total", fmt.Sprintf("%.2f", target), fmt.Sprintf("%.2f", actual), signedHours(actual - target), " ", signedHours(balance)})
+	table.Render()
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleValidFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		day      string
+		valid    bool
+	}{
+		{"open", Schedule{}, "2026-03-02", true},
+		{"before start", Schedule{From: "2026-07-01"}, "2026-06-30", false},
+		{"on start", Schedule{From: "2026-07-01"}, "2026-07-01", true},
+		{"on end", Schedule{To: "2026-06-30"}, "2026-06-30", true},
+		{"after end", Schedule{From: "2026-01-01", To: "2026-06-30"}, "2026-07-01", false},
+	}
+
+	for _, test := range tests {
+		day, _ := time.Parse("2006-01-02", test.day)
+		valid, err := test.schedule.validFor(day)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err)
+			continue
+		}
+		if valid != test.valid {
+			t.Errorf("%s: expected %v, got %v", test.name, test.valid, valid)
+		}
+	}
+
+	if _, err := (Schedule{From: "July"}).validFor(time.Now()); err == nil {
+		t.Errorf("expected an error for an invalid start")
+	}
+}
+
+func testCalendar(t *testing.T) *WorkCalendar {
+	holidays, err := newHolidayCalendar(HolidayConfig{Country: "DE", Region: "BY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &WorkCalendar{
+		Schedules: []Schedule{
+			{From: "2026-07-01", Hours: map[string]float64{"mon": 6, "tue": 6, "wed": 6, "thu": 6}},
+			{From: "2026-01-01", To: "2026-06-30", Hours: map[string]float64{"mon": 8, "tue": 8, "wed": 8, "thu": 8, "fri": 8}},
+		},
+		Holidays: holidays,
+	}
+}
+
+func TestWorkCalendarTarget(t *testing.T) {
+	wc := testCalendar(t)
+
+	tests := []struct {
+		day    string
+		target float64
+	}{
+		{"2026-06-26", 8}, // Friday of the first schedule
+		{"2026-06-27", 0}, // Saturday
+		{"2026-07-03", 0}, // Friday of the second schedule
+		{"2026-07-06", 6}, // Monday of the second schedule
+		{"2026-06-04", 0}, // Fronleichnam in Bavaria
+		{"2025-12-31", 0}, // before any schedule
+	}
+
+	for _, test := range tests {
+		day, _ := time.Parse("2006-01-02", test.day)
+		target, err := wc.Target(day)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.day, err)
+			continue
+		}
+		if target != test.target {
+			t.Errorf("%s: expected %.1f hours, got %.1f", test.day, test.target, target)
+		}
+	}
+}
+
+func TestBalance(t *testing.T) {
+	wc := testCalendar(t)
+	day := func(date string, hour int) time.Time {
+		d, _ := time.Parse("2006-01-02", date)
+		return d.Add(time.Duration(hour) * time.Hour)
+	}
+
+	el := EntryList{Entries: []TimeEntry{
+		{Start: day("2026-06-29", 8), Hours: 9 * time.Hour},                             // Monday, 8h target
+		{Start: day("2026-06-30", 8), Hours: 4 * time.Hour, Tags: []string{"vacation"}}, // absent
+		{Start: day("2026-07-02", 8), Hours: 5 * time.Hour},                             // Thursday, 6h target
+		{Start: day("2026-07-03", 8), Hours: 2 * time.Hour},                             // Friday, no target
+	}}
+
+	rows, err := el.balance(wc, []string{"vacation"}, day("2026-06-29", 0), day("2026-07-06", 0), "week")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected one week, got %d rows", len(rows))
+	}
+
+	// Wednesday 07-01 has a target of 6 hours without any entry
+	row := rows[0]
+	if row.Target != 20 || row.Actual != 16 || row.Absent != 1 || row.Balance != -4 {
+		t.Errorf("unexpected row %+v", row)
+	}
+
+	rows, err = el.balance(wc, nil, day("2026-06-29", 0), day("2026-07-01", 0), "day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1].Target != 8 || rows[1].Balance != -3 {
+		t.Errorf("expected the vacation to count without absence tags, got %+v", rows)
+	}
+
+	if _, err := el.balance(wc, nil, day("2026-06-29", 0), day("2026-07-01", 0), "year"); err == nil {
+		t.Errorf("expected an error for an invalid period")
+	}
+}
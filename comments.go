@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// commentPrefix marks a tag as comment, also when it is a single word.
+const commentPrefix = "c:"
+
+// CommentConfig controls how comments are read from the timewarrior tags.
+type CommentConfig struct {
+	// Commas is `keep` (the default) to keep comments as they are, or `split`
+	// to turn the comma separated words of a comment into tags.
+	Commas string `json:"commas"`
+	// Separator joins several comments of an interval, `; ` by default.
+	Separator string `json:"separator"`
+}
+
+// comments is the comment configuration used when parsing intervals.
+var comments CommentConfig
+
+func (cc CommentConfig) check() error {
+	switch cc.Commas {
+	case "", "keep", "split":
+		return nil
+	}
+	return fmt.Errorf("invalid comma handling %q, please use 'keep' or 'split'", cc.Commas)
+}
+
+func (cc CommentConfig) separator() string {
+	if cc.Separator == "" {
+		return "; "
+	}
+	return cc.Separator
+}
+
+// isCommentTag reports whether the tag holds a comment: it has the comment
+// prefix or contains a space.
+func isCommentTag(tag string) bool {
+	return strings.HasPrefix(tag, commentPrefix) || strings.Contains(tag, " ")
+}
+
+// commentTagFor returns the tag storing the comment. Comments which would not
+// be read back as they are get the comment prefix.
+func commentTagFor(comment string) string {
+	if !strings.Contains(comment, " ") || strings.Contains(comment, ",") || strings.HasPrefix(comment, commentPrefix) {
+		return commentPrefix + comment
+	}
+	return comment
+}
+
+// parseComment separates the comment from the other tags. The annotation
+// comes first, followed by the comment tags in their order.
+func (cc CommentConfig) parseComment(tags []string, annotation string) (string, []string) {
+	parts := []string{}
+	if annotation = strings.TrimSpace(annotation); annotation != "" {
+		parts = append(parts, annotation)
+	}
+
+	other := []string{}
+	for _, tag := range tags {
+		switch {
+		case strings.HasPrefix(tag, commentPrefix):
+			// explicit comments are never split
+			if comment := strings.TrimSpace(strings.TrimPrefix(tag, commentPrefix)); comment != "" {
+				parts = append(parts, comment)
+			}
+		case strings.Contains(tag, " ") && cc.Commas == "split":
+			words := []string{}
+			for _, part := range strings.Split(tag, ",") {
+				part = strings.TrimSpace(part)
+				switch {
+				case strings.Contains(part, " "):
+					words = append(words, part)
+				case part != "":
+					other = append(other, part)
+				}
+			}
+			if len(words) > 0 {
+				parts = append(parts, strings.Join(words, ", "))
+			}
+		case strings.Contains(tag, " "):
+			parts = append(parts, strings.TrimSpace(tag))
+		default:
+			other = append(other, tag)
+		}
+	}
+
+	return strings.Join(parts, cc.separator()), other
+}
@@ -0,0 +1,86 @@
+package main
+
+import "testing"
+
+func TestParseComment(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     CommentConfig
+		tags       []string
+		annotation string
+		comment    string
+		other      []string
+	}{
+		{"sentence", CommentConfig{}, []string{"R_1", "fixed the login"}, "", "fixed the login", []string{"R_1"}},
+		{"commas are kept", CommentConfig{}, []string{"fixed login, cleanup"}, "", "fixed login, cleanup", []string{}},
+		{"commas are split", CommentConfig{Commas: "split"}, []string{"fixed login, cleanup"}, "", "fixed login", []string{"cleanup"}},
+		{"single word", CommentConfig{}, []string{"c:cleanup", "meeting"}, "", "cleanup", []string{"meeting"}},
+		{"explicit comment is not split", CommentConfig{Commas: "split"}, []string{"c:fixed login, cleanup"}, "", "fixed login, cleanup", []string{}},
+		{"empty explicit comment", CommentConfig{}, []string{"c:", "R_1"}, "", "", []string{"R_1"}},
+		{"several comments", CommentConfig{}, []string{"fixed the login", "R_1", "c:cleanup"}, "", "fixed the login; cleanup", []string{"R_1"}},
+		{"separator", CommentConfig{Separator: " / "}, []string{"fixed the login", "c:cleanup"}, "", "fixed the login / cleanup", []string{}},
+		{"annotation", CommentConfig{}, []string{"R_1"}, "reviewed the release", "reviewed the release", []string{"R_1"}},
+		{"annotation first", CommentConfig{}, []string{"c:cleanup"}, " reviewed the release ", "reviewed the release; cleanup", []string{}},
+	}
+
+	for _, test := range tests {
+		comment, other := test.config.parseComment(test.tags, test.annotation)
+		if comment != test.comment {
+			t.Errorf("%s: expected comment %q, got %q", test.name, test.comment, comment)
+		}
+		if len(other) != len(test.other) {
+			t.Errorf("%s: expected tags %q, got %q", test.name, test.other, other)
+			continue
+		}
+		for i := range other {
+			if other[i] != test.other[i] {
+				t.Errorf("%s: expected tags %q, got %q", test.name, test.other, other)
+				break
+			}
+		}
+	}
+}
+
+func TestParseUsesAnnotation(t *testing.T) {
+	entry, err := parse(TimeWarriorEntry{
+		ID:         1,
+		Start:      "20261005T080000Z",
+		End:        "20261005T100000Z",
+		Tags:       []string{"R_1", "c:standup"},
+		Annotation: "daily sync",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	if entry.Comment != "daily sync; standup" {
+		t.Errorf("Expected the annotation and comment tag, got %q", entry.Comment)
+	}
+	if len(entry.Tags) != 0 || len(entry.IssueIDs) != 1 {
+		t.Errorf("Expected only the issue besides the comment, got %v and %v", entry.Tags, entry.IssueIDs)
+	}
+}
+
+func TestCommentTagFor(t *testing.T) {
+	tests := map[string]string{
+		"fixed the login":      "fixed the login",
+		"cleanup":              "c:cleanup",
+		"fixed login, cleanup": "c:fixed login, cleanup",
+		"c: is a prefix":       "c:c: is a prefix",
+	}
+	for comment, expected := range tests {
+		tag := commentTagFor(comment)
+		if tag != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, comment, tag)
+		}
+
+		parsed, _ := CommentConfig{Commas: "split"}.parseComment([]string{tag}, "")
+		if parsed != comment {
+			t.Errorf("Expected %q to be read back, got %q", comment, parsed)
+		}
+	}
+
+	if err := (CommentConfig{Commas: "drop"}).check(); err == nil {
+		t.Errorf("Expected an error for invalid comma handling")
+	}
+}
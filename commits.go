@@ -0,0 +1,333 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+// GitConfig lists the local repositories whose commits describe the work of
+// the entries.
+type GitConfig struct {
+	Repositories []string `json:"repositories"`
+	// Author filters the commits, the `user.email` of each repository by
+	// default.
+	Author string `json:"author"`
+	// Comments is `empty` (the default) to only propose comments for entries
+	// without one, or `append` to add the commits to existing comments.
+	Comments string `json:"comments"`
+}
+
+// Commit is a commit of a local repository.
+type Commit struct {
+	Repository string
+	Hash       string
+	// Time is the author time.
+	Time    time.Time
+	Subject string
+	// Issues are the issues referenced in the subject, like `#123`.
+	Issues []string
+}
+
+func (gc GitConfig) check() error {
+	switch gc.Comments {
+	case "", "empty", "append":
+		return nil
+	}
+	return fmt.Errorf("invalid git comment handling %q, please use 'empty' or 'append'", gc.Comments)
+}
+
+var commitIssueRexp = regexp.MustCompile(`(^|[^\w&])(#\d+|` + jiraKeyPattern + `)\b`)
+
+// commitIssues returns the issues referenced in the commit message.
+func commitIssues(message string) []string {
+	issues := []string{}
+	for _, match := range commitIssueRexp.FindAllStringSubmatch(message, -1) {
+		known := false
+		for _, issueID := range issues {
+			known = known || issueID == match[2]
+		}
+		if !known {
+			issues = append(issues, match[2])
+		}
+	}
+	return issues
+}
+
+// gitLogFormat prints the hash, the author time and the subject separated by
+// tabs. The author time is when the work was done, the commit time changes
+// with every rebase or cherry-pick.
+const gitLogFormat = "%H%x09%at%x09%s"
+
+func parseGitLog(repository, output string) ([]Commit, error) {
+	commits := []Commit{}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid git log line %q", line)
+		}
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit time %q", fields[1])
+		}
+
+		commits = append(commits, Commit{
+			Repository: repository,
+			Hash:       fields[0],
+			Time:       time.Unix(seconds, 0),
+			Subject:    strings.TrimSpace(fields[2]),
+			Issues:     commitIssues(fields[2]),
+		})
+	}
+	return commits, nil
+}
+
+// repositoryPath expands a leading `~` of the configured path.
+func repositoryPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+func git(repository string, args ...string) (string, error) {
+	output, err := exec.Command("git", append([]string{"-C", repository}, args...)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s in %s failed: %s", args[0], repository, err)
+	}
+	return string(output), nil
+}
+
+// commits reads the commits authored between from and to from all
+// repositories, ordered by time. Repositories which can not be read are
+// skipped with a warning.
+func (gc GitConfig) commits(from, to time.Time) []Commit {
+	commits := []Commit{}
+	for _, repository := range gc.Repositories {
+		path := repositoryPath(repository)
+
+		author := gc.Author
+		if author == "" {
+			email, err := git(path, "config", "user.email")
+			if err != nil {
+				log.Printf("Skipping %s: %s", repository, err)
+				continue
+			}
+			author = strings.TrimSpace(email)
+		}
+
+		// git filters by commit time, which is never before the author
+		// time, so only the start can be passed on
+		output, err := git(path, "log", "--all", "--no-merges",
+			"--author="+author,
+			fmt.Sprintf("--since=@%d", from.Unix()),
+			"--format="+gitLogFormat,
+		)
+		if err != nil {
+			log.Printf("Skipping %s: %s", repository, err)
+			continue
+		}
+
+		found, err := parseGitLog(repository, output)
+		if err != nil {
+			log.Printf("Skipping %s: %s", repository, err)
+			continue
+		}
+		for _, commit := range found {
+			if !commit.Time.Before(from) && !commit.Time.After(to) {
+				commits = append(commits, commit)
+			}
+		}
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Time.Before(commits[j].Time)
+	})
+	return commits
+}
+
+// span returns the time from the first start to the last end of the
+// entries.
+func span(entries []TimeEntry) (time.Time, time.Time) {
+	from, to := entries[0].Start, entries[0].End
+	for _, entry := range entries {
+		if entry.Start.Before(from) {
+			from = entry.Start
+		}
+		if entry.End.After(to) {
+			to = entry.End
+		}
+	}
+	return from, to
+}
+
+// commitsOf returns the commits made while the entry was tracked.
+func commitsOf(te TimeEntry, commits []Commit) []Commit {
+	result := []Commit{}
+	for _, commit := range commits {
+		if !commit.Time.Before(te.Start) && !commit.Time.After(te.End) {
+			result = append(result, commit)
+		}
+	}
+	return result
+}
+
+// proposeComment builds the comment of the entry from its commits. It
+// returns false when the comment stays as it is.
+func (gc GitConfig) proposeComment(te TimeEntry, commits []Commit) (string, bool) {
+	if len(commits) == 0 || (te.Comment != "" && gc.Comments != "append") {
+		return te.Comment, false
+	}
+
+	parts := []string{}
+	if te.Comment != "" {
+		parts = append(parts, te.Comment)
+	}
+	for _, commit := range commits {
+		known := false
+		for _, part := range parts {
+			known = known || strings.Contains(part, commit.Subject)
+		}
+		if !known {
+			parts = append(parts, commit.Subject)
+		}
+	}
+
+	comment := strings.Join(parts, comments.separator())
+	return comment, comment != te.Comment
+}
+
+// commitSuggestions suggests the issues referenced by the commits of the
+// entry.
+func commitSuggestions(te TimeEntry, commits []Commit) []Suggestion {
+	suggestions := []Suggestion{}
+	seen := map[string]bool{}
+	for _, commit := range commitsOf(te, commits) {
+		for _, issueID := range commit.Issues {
+			if seen[issueID] {
+				continue
+			}
+			seen[issueID] = true
+			suggestions = append(suggestions, Suggestion{
+				IssueID: issueID,
+				Reason:  "commit " + commit.Hash[:min(len(commit.Hash), 7)],
+			})
+		}
+	}
+	return suggestions
+}
+
+func commitsCommand() cli.Command {
+	return cli.Command{
+		Name:  "commits",
+		Usage: "Propose comments and issues from the git commits made while the entries were tracked.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "range",
+				Value: "week",
+				Usage: "The time range of the entries. Valid ranges are 'all', 'month', 'week', and 'day'.",
+			},
+			&cli.BoolFlag{
+				Name:  "apply",
+				Usage: "Write the proposed comments to timewarrior.",
+			},
+			&cli.BoolFlag{
+				Name:  "tag",
+				Usage: "Tag untagged entries whose commits reference a single issue.",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			aliases, err := aliasMap()
+			if err != nil {
+				return err
+			}
+
+			time_range := ctx.String("range")
+			if time_range != "all" && time_range != "month" && time_range != "week" && time_range != "day" {
+				return fmt.Errorf("invalid time range, please use 'all', 'month', 'week', or 'day'")
+			}
+
+			config, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if len(config.Git.Repositories) == 0 {
+				return fmt.Errorf("no git repositories configured")
+			}
+
+			// the comments and tags belong to the intervals as stored in timewarrior
+			el := EntryList{Aliases: aliases, raw: true}
+			if err := el.fromTimeWarrior(time_range); err != nil {
+				return err
+			}
+			if len(el.Entries) == 0 {
+				return nil
+			}
+
+			commits := config.Git.commits(span(el.Entries))
+
+			loc := localTime()
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"ID", "Date", "Commits", "Comment", "Issues"})
+			table.SetAutoWrapText(false)
+
+			for _, entry := range el.Entries {
+				found := commitsOf(entry, commits)
+				if len(found) == 0 {
+					continue
+				}
+
+				comment, changed := config.Git.proposeComment(entry, found)
+				issues := []string{}
+				for _, suggestion := range commitSuggestions(entry, found) {
+					issues = append(issues, suggestion.IssueID)
+				}
+				if len(entry.IssueIDs) > 0 {
+					issues = nil
+				}
+				table.Append([]string{
+					entry.ID,
+					entry.Start.In(loc).Format("2006-01-02 15:04"),
+					strconv.Itoa(len(found)),
+					comment,
+					strings.Join(issues, ", "),
+				})
+
+				synced := entry.hasSyncMarker("S2R") || entry.hasSyncMarker("S2J")
+				if (ctx.Bool("apply") || ctx.Bool("tag")) && synced {
+					log.Printf("Interval %s is already synced, please use edit --propagate", entry.ID)
+					continue
+				}
+				if ctx.Bool("apply") && changed {
+					if err := entry.setComment(comment); err != nil {
+						return err
+					}
+				}
+				if ctx.Bool("tag") && len(issues) == 1 {
+					if err := entry.retag(nil, []string{issueTag(issues[0])}); err != nil {
+						return err
+					}
+				}
+			}
+			table.Render()
+
+			return nil
+		},
+	}
+}
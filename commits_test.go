@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestCommitIssues(t *testing.T) {
+	tests := []struct {
+		message string
+		issues  []string
+	}{
+		{"Fix the login (#123)", []string{"#123"}},
+		{"PIM-45: refs #123 and #123", []string{"PIM-45", "#123"}},
+		{"OPS-45 moved from PIM-45", []string{"OPS-45", "PIM-45"}},
+		{"Rename PIM-45a", []string{}},
+		{"Escape &#39; in titles", []string{}},
+		{"Cleanup", []string{}},
+	}
+
+	for _, test := range tests {
+		issues := commitIssues(test.message)
+		if len(issues) != len(test.issues) {
+			t.Errorf("%q: expected %q, got %q", test.message, test.issues, issues)
+			continue
+		}
+		for i := range issues {
+			if issues[i] != test.issues[i] {
+				t.Errorf("%q: expected %q, got %q", test.message, test.issues, issues)
+				break
+			}
+		}
+	}
+}
+
+func TestParseGitLog(t *testing.T) {
+	output := "abc1234567\t1791187200\tFix the login (#123)\n\ndef7654321\t1791190800\tUpdate\tdocs\n"
+	commits, err := parseGitLog("app", output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(commits))
+	}
+	if commits[0].Hash != "abc1234567" || commits[0].Time.Unix() != 1791187200 || len(commits[0].Issues) != 1 {
+		t.Errorf("unexpected first commit %+v", commits[0])
+	}
+	if commits[1].Subject != "Update\tdocs" {
+		t.Errorf("expected the subject with tab, got %q", commits[1].Subject)
+	}
+
+	if _, err := parseGitLog("app", "abc\tnow\tFix"); err == nil {
+		t.Errorf("expected an error for an invalid time")
+	}
+}
+
+func TestProposeComment(t *testing.T) {
+	start := time.Date(2026, 10, 5, 8, 0, 0, 0, time.UTC)
+	entry := TimeEntry{Start: start, End: start.Add(2 * time.Hour)}
+	commits := []Commit{
+		{Hash: "a", Time: start.Add(-time.Minute), Subject: "Before"},
+		{Hash: "b", Time: start.Add(time.Hour), Subject: "Fix the login (#123)", Issues: []string{"#123"}},
+		{Hash: "c", Time: start.Add(2 * time.Hour), Subject: "Add tests", Issues: []string{"#123"}},
+	}
+
+	found := commitsOf(entry, commits)
+	if len(found) != 2 {
+		t.Fatalf("expected 2 commits of the entry, got %d", len(found))
+	}
+
+	comment, changed := GitConfig{}.proposeComment(entry, found)
+	if !changed || comment != "Fix the login (#123); Add tests" {
+		t.Errorf("unexpected comment %q (%v)", comment, changed)
+	}
+
+	entry.Comment = "Add tests"
+	if comment, changed := (GitConfig{}).proposeComment(entry, found); changed || comment != "Add tests" {
+		t.Errorf("expected the comment to be kept, got %q", comment)
+	}
+	if comment, _ := (GitConfig{Comments: "append"}).proposeComment(entry, found); comment != "Add tests; Fix the login (#123)" {
+		t.Errorf("unexpected appended comment %q", comment)
+	}
+
+	suggestions := commitSuggestions(entry, commits)
+	if len(suggestions) != 1 || suggestions[0].IssueID != "#123" || suggestions[0].Reason != "commit b" {
+		t.Errorf("unexpected suggestions %+v", suggestions)
+	}
+}
+
+func TestCommitsByAuthorTime(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repository := t.TempDir()
+	start := time.Date(2026, 10, 5, 8, 0, 0, 0, time.UTC)
+	run := func(env []string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repository}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s %s", args, err, output)
+		}
+	}
+	run(nil, "init", "-q")
+	// written during the entry, but rebased a week later
+	run([]string{
+		"GIT_AUTHOR_NAME=dev", "GIT_AUTHOR_EMAIL=dev@example.com", "GIT_AUTHOR_DATE=" + start.Add(time.Hour).Format(time.RFC3339),
+		"GIT_COMMITTER_NAME=dev", "GIT_COMMITTER_EMAIL=dev@example.com", "GIT_COMMITTER_DATE=" + start.AddDate(0, 0, 7).Format(time.RFC3339),
+	}, "commit", "-q", "--allow-empty", "-m", "Fix the login (#123)")
+
+	gc := GitConfig{Repositories: []string{repository}, Author: "dev@example.com"}
+	commits := gc.commits(start, start.Add(2*time.Hour))
+	if len(commits) != 1 || !commits[0].Time.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected the commit at its author time, got %+v", commits)
+	}
+	if commits := gc.commits(start.AddDate(0, 0, 7), start.AddDate(0, 0, 8)); len(commits) != 0 {
+		t.Errorf("expected no commit at the commit time, got %+v", commits)
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// BreakRule requires a break of at least Minutes when more than AfterHours
+// are worked on a day.
+type BreakRule struct {
+	AfterHours float64 `json:"afterHours"`
+	Minutes    float64 `json:"minutes"`
+}
+
+// ComplianceConfig holds the working time limits. Unset values are taken
+// from the profile of the jurisdiction.
+type ComplianceConfig struct {
+	Jurisdiction  string  `json:"jurisdiction"`
+	MaxDailyHours float64 `json:"maxDailyHours"`
+	MinRestHours  float64 `json:"minRestHours"`
+	// MinBreakMinutes is the minimum length of a gap to count as a break.
+	MinBreakMinutes float64     `json:"minBreakMinutes"`
+	Breaks          []BreakRule `json:"breaks"`
+}
+
+var complianceProfiles = map[string]ComplianceConfig{
+	// Arbeitszeitgesetz (ArbZG) §§ 3, 4, 5
+	"DE": {
+		MaxDailyHours:   10,
+		MinRestHours:    11,
+		MinBreakMinutes: 15,
+		Breaks: []BreakRule{
+			{AfterHours: 6, Minutes: 30},
+			{AfterHours: 9, Minutes: 45},
+		},
+	},
+	// Arbeitszeitgesetz (AZG) §§ 9, 11, 12
+	"AT": {
+		MaxDailyHours:   10,
+		MinRestHours:    11,
+		MinBreakMinutes: 10,
+		Breaks: []BreakRule{
+			{AfterHours: 6, Minutes: 30},
+		},
+	},
+}
+
+func (cc ComplianceConfig) withDefaults() (ComplianceConfig, error) {
+	jurisdiction := cc.Jurisdiction
+	if jurisdiction == "" {
+		jurisdiction = "DE"
+	}
+
+	profile, ok := complianceProfiles[jurisdiction]
+	if !ok {
+		return cc, fmt.Errorf("unknown jurisdiction %q", jurisdiction)
+	}
+
+	profile.Jurisdiction = jurisdiction
+	if cc.MaxDailyHours > 0 {
+		profile.MaxDailyHours = cc.MaxDailyHours
+	}
+	if cc.MinRestHours > 0 {
+		profile.MinRestHours = cc.MinRestHours
+	}
+	if cc.MinBreakMinutes > 0 {
+		profile.MinBreakMinutes = cc.MinBreakMinutes
+	}
+	if len(cc.Breaks) > 0 {
+		profile.Breaks = cc.Breaks
+	}
+
+	sort.Slice(profile.Breaks, func(i, j int) bool {
+		return profile.Breaks[i].AfterHours < profile.Breaks[j].AfterHours
+	})
+
+	return profile, nil
+}
+
+type Violation struct {
+	// EntryID is the entry the violation is attached to in `list`.
+	EntryID string
+	Message string
+	// entry is the key of the entry, as portions of an interval share its ID.
+	entry string
+}
+
+type ComplianceDay struct {
+	Day        string
+	Start      time.Time
+	End        time.Time
+	Worked     time.Duration
+	Breaks     time.Duration
+	Violations []Violation
+
+	lastEntryID  string
+	lastEntryKey string
+}
+
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// compliance analyses the intervals per day. Overlapping intervals are
+// merged, gaps of at least MinBreakMinutes count as breaks.
+func (el *EntryList) compliance(cc ComplianceConfig) []ComplianceDay {
+	loc := localTime()
+	entries := append([]TimeEntry{}, el.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+
+	minBreak := time.Duration(cc.MinBreakMinutes * float64(time.Minute))
+	maxDaily := time.Duration(cc.MaxDailyHours * float64(time.Hour))
+	minRest := time.Duration(cc.MinRestHours * float64(time.Hour))
+
+	days := []ComplianceDay{}
+	var day *ComplianceDay
+	var stretch time.Duration
+	stretchReported := false
+	for _, entry := range entries {
+		date := entry.Start.In(loc).Format("2006-01-02")
+		if day == nil || day.Day != date {
+			if day != nil {
+				days = append(days, *day)
+			}
+
+			previous := day
+			day = &ComplianceDay{Day: date, Start: entry.Start, End: entry.Start}
+			stretch = 0
+			stretchReported = false
+
+			// a portion of an interval crossing midnight continues the previous
+			// day and is no rest period
+			if previous != nil && previous.lastEntryID != entry.ID {
+				if rest := entry.Start.Sub(previous.End); rest < minRest {
+					day.Violations = append(day.Violations, Violation{
+						EntryID: entry.ID,
+						entry:   entry.key(),
+						Message: fmt.Sprintf("Rest period of %s is shorter than %.0fh", formatDuration(rest), cc.MinRestHours),
+					})
+				}
+			}
+		} else {
+			gap := entry.Start.Sub(day.End)
+			if gap >= minBreak {
+				day.Breaks += gap
+				stretch = 0
+			}
+		}
+
+		// only count the part which does not overlap the previous intervals
+		start := entry.Start
+		if start.Before(day.End) {
+			start = day.End
+		}
+		if entry.End.After(start) {
+			worked := entry.End.Sub(start)
+			before := day.Worked
+			day.Worked += worked
+			stretch += worked
+
+			if before <= maxDaily && day.Worked > maxDaily {
+				day.Violations = append(day.Violations, Violation{
+					EntryID: entry.ID,
+					Message: fmt.Sprintf("Worked more than %.0fh", cc.MaxDailyHours),
+					entry:   entry.key(),
+				})
+			}
+
+			if len(cc.Breaks) > 0 && !stretchReported {
+				limit := time.Duration(cc.Breaks[0].AfterHours * float64(time.Hour))
+				if stretch > limit {
+					stretchReported = true
+					day.Violations = append(day.Violations, Violation{
+						EntryID: entry.ID,
+						entry:   entry.key(),
+						Message: fmt.Sprintf("Worked more than %.0fh without a break", cc.Breaks[0].AfterHours),
+					})
+				}
+			}
+		}
+
+		if entry.End.After(day.End) {
+			day.End = entry.End
+		}
+		day.lastEntryID = entry.ID
+		day.lastEntryKey = entry.key()
+	}
+	if day != nil {
+		days = append(days, *day)
+	}
+
+	for i := range days {
+		days[i].checkBreaks(cc)
+	}
+
+	return days
+}
+
+// checkBreaks applies the strictest break rule which is due. The break rules
+// can only be judged once the day is complete, so the violation is attached
+// to the last entry of the day.
+func (day *ComplianceDay) checkBreaks(cc ComplianceConfig) {
+	for i := len(cc.Breaks) - 1; i >= 0; i-- {
+		rule := cc.Breaks[i]
+		required := time.Duration(rule.Minutes * float64(time.Minute))
+		if day.Worked.Hours() > rule.AfterHours && day.Breaks < required {
+			day.Violations = append(day.Violations, Violation{
+				EntryID: day.lastEntryID,
+				entry:   day.lastEntryKey,
+				Message: fmt.Sprintf("Break of %s is shorter than %.0fmin after %.0fh", formatDuration(day.Breaks), rule.Minutes, rule.AfterHours),
+			})
+			return
+		}
+	}
+}
+
+// complianceCheck exposes the compliance violations as problems in `list`.
+func complianceCheck(cc ComplianceConfig) Check {
+	return func(entries []TimeEntry) map[string][]string {
+		el := EntryList{Entries: entries}
+		problems := map[string][]string{}
+		for _, day := range el.compliance(cc) {
+			for _, violation := range day.Violations {
+				problems[violation.entry] = append(problems[violation.entry], violation.Message)
+			}
+		}
+		return problems
+	}
+}
+
+func writeCompliance(w io.Writer, days []ComplianceDay, all bool) int {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Day", "Start", "End", "Worked", "Breaks", "Violations"})
+
+	loc := localTime()
+	count := 0
+	for _, day := range days {
+		if len(day.Violations) == 0 && !all {
+			continue
+		}
+
+		messages := []string{}
+		for _, violation := range day.Violations {
+			messages = append(messages, violation.Message)
+		}
+		count += len(messages)
+
+		table.Append([]string{
+			day.Day,
+			day.Start.In(loc).Format("15:04"),
+			day.End.In(loc).Format("15:04"),
+			formatDuration(day.Worked),
+			formatDuration(day.Breaks),
+			strings.Join(messages, "\n"),
+		})
+	}
+
+	table.SetFooter([]string{" ", " ", " ", " ", "Violations", fmt.Sprintf("%d", count)})
+	table.Render()
+
+	return count
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func complianceEntry(id, start, end string) TimeEntry {
+	s, _ := time.ParseInLocation("2006-01-02 15:04", start, localTime())
+	e, _ := time.ParseInLocation("2006-01-02 15:04", end, localTime())
+	return TimeEntry{ID: id, Start: s, End: e, Hours: e.Sub(s)}
+}
+
+func TestCompliance(t *testing.T) {
+	cc, err := ComplianceConfig{}.withDefaults()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	el := EntryList{Entries: []TimeEntry{
+		// 11:30h with a 20 minute break
+		complianceEntry("1", "2026-10-05 08:00", "2026-10-05 12:00"),
+		complianceEntry("2", "2026-10-05 12:20", "2026-10-05 17:50"),
+		complianceEntry("3", "2026-10-05 18:00", "2026-10-05 20:00"),
+		// starts after 10 hours of rest
+		complianceEntry("4", "2026-10-06 06:00", "2026-10-06 12:00"),
+	}}
+
+	days := el.compliance(cc)
+	if len(days) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(days))
+	}
+
+	if days[0].Worked != 11*time.Hour+30*time.Minute {
+		t.Errorf("Expected 11:30 worked, got %s", formatDuration(days[0].Worked))
+	}
+	if days[0].Breaks != 20*time.Minute {
+		t.Errorf("Expected 0:20 breaks, got %s", formatDuration(days[0].Breaks))
+	}
+
+	messages := map[string]int{}
+	for _, day := range days {
+		for _, violation := range day.Violations {
+			messages[violation.EntryID]++
+		}
+	}
+
+	// entry 3 exceeds 10h, works 7:30 since the last break and carries the
+	// break violation of the day
+	if messages["3"] != 3 {
+		t.Errorf("Expected 3 violations on entry 3, got %d", messages["3"])
+	}
+	// entry 4 violates the rest period
+	if messages["4"] != 1 {
+		t.Errorf("Expected 1 violation on entry 4, got %d", messages["4"])
+	}
+	if messages["1"] != 0 {
+		t.Errorf("Expected no violation on entry 1, got %d", messages["1"])
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/adrg/xdg"
+)
+
+// Config holds the settings which do not fit into environment variables. It
+// is read from `worklogger/config.json` in the XDG config directory; a missing
+// file results in an empty configuration.
+type Config struct {
+	Balance    BalanceConfig    `json:"balance"`
+	Holidays   HolidayConfig    `json:"holidays"`
+	Compliance ComplianceConfig `json:"compliance"`
+	Gaps       GapConfig        `json:"gaps"`
+	Rules      []Rule           `json:"rules"`
+	Comments   CommentConfig    `json:"comments"`
+	Templates  TemplateConfig   `json:"templates"`
+	Git        GitConfig        `json:"git"`
+}
+
+func loadConfig() (*Config, error) {
+	config := &Config{}
+
+	configFile, err := xdg.ConfigFile("worklogger/config.json")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// jiraKeyPattern matches the issue keys of all JIRA projects, as issues can be
+// moved from PIM to other projects.
+const jiraKeyPattern = `[A-Z][A-Z0-9]*-\d+`
+
+var jiraKeyRexp = regexp.MustCompile(`^` + jiraKeyPattern + `$`)
+
+// hasIssuePrefix reports whether the issue belongs to the tracker of the
+// prefix. For JIRA, `PIM-`, this includes the keys of other projects.
+func hasIssuePrefix(issueID, prefix string) bool {
+	if prefix == "PIM-" {
+		return jiraKeyRexp.MatchString(issueID)
+	}
+	return strings.HasPrefix(issueID, prefix)
+}
+
+// issuesWithPrefix returns the issue IDs of one tracker, e.g. `#` for Redmine.
+func (te *TimeEntry) issuesWithPrefix(prefix string) []string {
+	issueIDs := []string{}
+	for _, issueID := range te.IssueIDs {
+		if hasIssuePrefix(issueID, prefix) {
+			issueIDs = append(issueIDs, issueID)
+		}
+	}
+	return issueIDs
+}
+
+// shares returns the fraction of the hours per issue of one tracker. Without
+// weights the hours are split evenly. The split is ambiguous when only some
+// of the issues carry a weight.
+func (te *TimeEntry) shares(prefix string) (map[string]float64, error) {
+	issueIDs := te.issuesWithPrefix(prefix)
+	shares := map[string]float64{}
+
+	weighted := 0
+	total := 0.0
+	for _, issueID := range issueIDs {
+		if weight, ok := te.Weights[issueID]; ok {
+			if weight <= 0 {
+				return nil, fmt.Errorf("weight of %s must be positive", issueID)
+			}
+			weighted++
+			total += weight
+		}
+	}
+
+	if weighted > 0 && weighted < len(issueIDs) {
+		return nil, fmt.Errorf("ambiguous split, weights missing for some of %s", strings.Join(issueIDs, ", "))
+	}
+
+	for _, issueID := range issueIDs {
+		if weighted == 0 {
+			shares[issueID] = 1 / float64(len(issueIDs))
+		} else {
+			shares[issueID] = te.Weights[issueID] / total
+		}
+	}
+
+	return shares, nil
+}
+
+// distribute returns one entry per issue of a tracker with its share of the
+// hours. Each part only references its own issue of that tracker.
+func (te TimeEntry) distribute(prefix string) ([]TimeEntry, error) {
+	issueIDs := te.issuesWithPrefix(prefix)
+	if len(issueIDs) <= 1 {
+		return []TimeEntry{te}, nil
+	}
+
+	shares, err := te.shares(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := []TimeEntry{}
+	remaining := te.Hours
+	for i, issueID := range issueIDs {
+		part := te
+		part.IssueIDs = []string{issueID}
+		for _, other := range te.IssueIDs {
+			if !hasIssuePrefix(other, prefix) {
+				part.IssueIDs = append(part.IssueIDs, other)
+			}
+		}
+
+		// the last part gets the remainder, so no time is lost to rounding
+		part.Hours = time.Duration(float64(te.Hours) * shares[issueID]).Round(time.Second)
+		if i == len(issueIDs)-1 {
+			part.Hours = remaining
+		}
+		remaining -= part.Hours
+
+		parts = append(parts, part)
+	}
+
+	return parts, nil
+}
+
+// distributionCheck reports entries whose hours can not be split clearly.
+func distributionCheck(entries []TimeEntry) map[string][]string {
+	problems := map[string][]string{}
+	for _, entry := range entries {
+		for _, prefix := range []string{"#", "PIM-"} {
+			if _, err := entry.shares(prefix); err != nil {
+				problems[entry.key()] = append(problems[entry.key()], err.Error())
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeEntryDistribute(t *testing.T) {
+	entry, err := parse(TimeWarriorEntry{
+		ID:    1,
+		Start: "20261005T080000Z",
+		End:   "20261005T100000Z",
+		Tags:  []string{"R_100:70", "R_200:30", "J_5", "fixed the login"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	parts, err := entry.distribute("#")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("Expected 2 parts, got %d", len(parts))
+	}
+
+	if parts[0].IssueIDs[0] != "#100" || parts[0].Hours != 84*time.Minute {
+		t.Errorf("Expected #100 with 1:24, got %s with %s", parts[0].IssueIDs[0], formatDuration(parts[0].Hours))
+	}
+	if parts[1].IssueIDs[0] != "#200" || parts[1].Hours != 36*time.Minute {
+		t.Errorf("Expected #200 with 0:36, got %s with %s", parts[1].IssueIDs[0], formatDuration(parts[1].Hours))
+	}
+	if len(parts[0].IssueIDs) != 2 || parts[0].IssueIDs[1] != "PIM-5" {
+		t.Errorf("Expected the JIRA issue to be kept, got %v", parts[0].IssueIDs)
+	}
+
+	jira, err := entry.distribute("PIM-")
+	if err != nil || len(jira) != 1 || jira[0].Hours != 2*time.Hour {
+		t.Errorf("Expected the JIRA issue to get all hours")
+	}
+
+	entry.Weights = map[string]float64{"#100": 70}
+	if _, err := entry.distribute("#"); err == nil {
+		t.Errorf("Expected an error for an ambiguous split")
+	}
+
+	entry.Weights = nil
+	parts, err = entry.distribute("#")
+	if err != nil || parts[0].Hours != time.Hour || parts[1].Hours != time.Hour {
+		t.Errorf("Expected an even split without weights")
+	}
+}
@@ -0,0 +1,594 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/urfave/cli"
+)
+
+// retag removes and adds tags of the interval in timewarrior.
+func (te *TimeEntry) retag(remove, add []string) error {
+	if len(remove) > 0 {
+		done := recordTags("untag", te.Start, te.timewTags, remove)
+		if err := timew(append([]string{"untag", "@" + te.ID}, remove...)...); err != nil {
+			return err
+		}
+		done()
+	}
+	if len(add) > 0 {
+		done := recordTags("tag", te.Start, replaceTags(te.timewTags, remove, nil), add)
+		if err := timew(append([]string{"tag", "@" + te.ID}, add...)...); err != nil {
+			return err
+		}
+		done()
+	}
+
+	te.timewTags = replaceTags(te.timewTags, remove, add)
+	return nil
+}
+
+func replaceTags(tags, remove, add []string) []string {
+	result := []string{}
+	for _, tag := range tags {
+		removed := false
+		for _, r := range remove {
+			if tag == r {
+				removed = true
+			}
+		}
+		if !removed {
+			result = append(result, tag)
+		}
+	}
+	return append(result, add...)
+}
+
+// commentTags returns the timewarrior tags holding the comment.
+func (te *TimeEntry) commentTags() []string {
+	tags := []string{}
+	for _, tag := range te.timewTags {
+		if isCommentTag(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// setComment replaces the comment of the interval. An annotated interval
+// keeps the comment in the annotation, otherwise a single comment tag is
+// written.
+func (te *TimeEntry) setComment(comment string) error {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return fmt.Errorf("the comment must not be empty")
+	}
+
+	if te.Annotation != "" {
+		if err := te.annotate(comment); err != nil {
+			return err
+		}
+		if err := te.retag(te.commentTags(), nil); err != nil {
+			return err
+		}
+	} else if err := te.retag(te.commentTags(), []string{commentTagFor(comment)}); err != nil {
+		return err
+	}
+
+	te.Comment = comment
+	return nil
+}
+
+// annotate replaces the annotation of the interval.
+func (te *TimeEntry) annotate(annotation string) error {
+	done := record(JournalChange{Action: "annotate", At: te.Start, OldAnnotation: te.Annotation, Annotation: annotation})
+	if err := timew("annotate", "@"+te.ID, annotation); err != nil {
+		return err
+	}
+	done()
+
+	te.Annotation = annotation
+	return nil
+}
+
+// setActivity replaces the `A_` tags of the interval.
+func (te *TimeEntry) setActivity(activityID string) error {
+	remove := []string{}
+	for _, tag := range te.timewTags {
+		if strings.HasPrefix(tag, "A_") {
+			remove = append(remove, tag)
+		}
+	}
+	if err := te.retag(remove, []string{"A_" + activityID}); err != nil {
+		return err
+	}
+
+	te.ActivityID = activityID
+	return nil
+}
+
+// issueTagOf finds the timewarrior tag referencing the issue, which may be
+// a plain `R_123`, a weighted `R_123:70` or an alias.
+func (te *TimeEntry) issueTagOf(issueID string, aliases map[string]Alias) string {
+	rexp := regexp.MustCompile(`^([RJ]_(?:[A-Z][A-Z0-9]*-)?\w+)(:\d+(\.\d+)?)?$`)
+	for _, tag := range te.timewTags {
+		if alias, ok := aliases[tag]; ok && alias.Issue == issueID {
+			return tag
+		}
+		if match := rexp.FindStringSubmatch(tag); match != nil && match[1] == issueTag(issueID) {
+			return tag
+		}
+	}
+	return ""
+}
+
+// replaceIssue swaps an issue of the interval for another one. A weight of
+// the old issue is kept.
+func (te *TimeEntry) replaceIssue(oldID, newID string, aliases map[string]Alias) error {
+	old := te.issueTagOf(oldID, aliases)
+	if old == "" {
+		return fmt.Errorf("no tag found for issue %s on interval %s", oldID, te.ID)
+	}
+
+	tag := issueTag(newID)
+	if i := strings.Index(old, ":"); i > 0 {
+		tag += old[i:]
+	}
+	if err := te.retag([]string{old}, []string{tag}); err != nil {
+		return err
+	}
+
+	for i, issueID := range te.IssueIDs {
+		if issueID == oldID {
+			te.IssueIDs[i] = newID
+		}
+	}
+	if weight, ok := te.Weights[oldID]; ok {
+		delete(te.Weights, oldID)
+		te.Weights[newID] = weight
+	}
+	te.IsRedmine = len(te.issuesWithPrefix("#")) > 0
+	te.IsJira = len(te.issuesWithPrefix("PIM-")) > 0
+
+	return nil
+}
+
+// Edit holds the changes of the edit command. Empty fields stay unchanged.
+type Edit struct {
+	Comment  string
+	Activity string
+	Issue    string
+}
+
+// edited returns the interval as apply leaves it, without changing
+// timewarrior, so edits can be checked before any of them is written.
+func (te TimeEntry) edited(edit Edit) (TimeEntry, error) {
+	te.IssueIDs = append([]string{}, te.IssueIDs...)
+	weights := map[string]float64{}
+	for issueID, weight := range te.Weights {
+		weights[issueID] = weight
+	}
+	te.Weights = weights
+
+	if edit.Comment != "" {
+		te.Comment = edit.Comment
+	}
+	if edit.Activity != "" {
+		te.ActivityID = edit.Activity
+	}
+	if edit.Issue == "" {
+		return te, nil
+	}
+
+	prefix := "#"
+	if hasIssuePrefix(edit.Issue, "PIM-") {
+		prefix = "PIM-"
+	}
+	issueIDs := te.issuesWithPrefix(prefix)
+	switch len(issueIDs) {
+	case 0:
+		te.IssueIDs = append(te.IssueIDs, edit.Issue)
+	case 1:
+		for i, issueID := range te.IssueIDs {
+			if issueID == issueIDs[0] {
+				te.IssueIDs[i] = edit.Issue
+			}
+		}
+		if weight, ok := te.Weights[issueIDs[0]]; ok {
+			delete(te.Weights, issueIDs[0])
+			te.Weights[edit.Issue] = weight
+		}
+	default:
+		return te, fmt.Errorf("interval %s has several issues (%s), please change it with `timew retag`", te.ID, strings.Join(issueIDs, ", "))
+	}
+	te.IsRedmine = len(te.issuesWithPrefix("#")) > 0
+	te.IsJira = len(te.issuesWithPrefix("PIM-")) > 0
+
+	return te, nil
+}
+
+// apply rewrites the timewarrior tags of the interval. A new issue replaces
+// the issue of the same tracker, or is added if there is none.
+func (te *TimeEntry) apply(edit Edit, aliases map[string]Alias) error {
+	if edit.Comment != "" && edit.Comment != te.Comment {
+		if err := te.setComment(edit.Comment); err != nil {
+			return err
+		}
+	}
+	if edit.Activity != "" && edit.Activity != te.ActivityID {
+		if err := te.setActivity(edit.Activity); err != nil {
+			return err
+		}
+	}
+	if edit.Issue == "" {
+		return nil
+	}
+
+	prefix := "#"
+	if hasIssuePrefix(edit.Issue, "PIM-") {
+		prefix = "PIM-"
+	}
+	issueIDs := te.issuesWithPrefix(prefix)
+	switch len(issueIDs) {
+	case 0:
+		if err := te.retag(nil, []string{issueTag(edit.Issue)}); err != nil {
+			return err
+		}
+		te.IssueIDs = append(te.IssueIDs, edit.Issue)
+		te.IsRedmine = te.IsRedmine || prefix == "#"
+		te.IsJira = te.IsJira || prefix == "PIM-"
+		return nil
+	case 1:
+		if issueIDs[0] == edit.Issue {
+			return nil
+		}
+		return te.replaceIssue(issueIDs[0], edit.Issue, aliases)
+	}
+
+	return fmt.Errorf("interval %s has several issues (%s), please change it with `timew retag`", te.ID, strings.Join(issueIDs, ", "))
+}
+
+// pushedParts returns the parts of the interval as they are pushed to
+// Redmine: one per day and issue.
+func pushedParts(te TimeEntry) ([]TimeEntry, error) {
+	parts := []TimeEntry{}
+	for _, portion := range te.splitAtMidnight(localTime()) {
+		distributed, err := portion.distribute("#")
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, distributed...)
+	}
+	return parts, nil
+}
+
+// matchTimeEntries finds the Redmine time entry of each part by day, issue
+// and hours, as the IDs of the time entries are not stored and the pushed
+// comment may be rendered by a template. Parts without a time entry get -1.
+func matchTimeEntries(parts []TimeEntry, entries []redmine.TimeEntryObject) []int {
+	used := map[int]bool{}
+	matches := []int{}
+	for _, part := range parts {
+		match := -1
+		issueIDs := part.issuesWithPrefix("#")
+		for i, entry := range entries {
+			if used[i] || len(issueIDs) == 0 || "#"+strconv.FormatInt(entry.Issue.ID, 10) != issueIDs[0] {
+				continue
+			}
+			if entry.SpentOn != part.Start.In(localTime()).Format("2006-01-02") || math.Abs(entry.Hours-part.Hours.Hours()) > 0.01 {
+				continue
+			}
+			match = i
+			used[i] = true
+			break
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// RedmineUpdate is a change of a pushed Redmine time entry.
+type RedmineUpdate struct {
+	ID     int64
+	Update redmine.TimeEntryUpdateObject
+}
+
+// propagation finds the Redmine time entries pushed for the interval and
+// the updates carrying the edit over, with the comment rendered like it is
+// pushed. Nothing is changed yet.
+func (rl RedmineLogger) propagation(original, edited TimeEntry, st SinkTemplate, issues map[string]IssueInfo) ([]RedmineUpdate, error) {
+	originalParts, err := pushedParts(original)
+	if err != nil {
+		return nil, err
+	}
+	editedParts, err := pushedParts(edited)
+	if err != nil {
+		return nil, err
+	}
+	if len(editedParts) != len(originalParts) {
+		return nil, fmt.Errorf("the issues of interval %s changed, please update its Redmine time entries by hand", original.ID)
+	}
+
+	api, err := rl.getApi()
+	if err != nil {
+		return nil, err
+	}
+
+	user, code, err := api.UserCurrentGet(redmine.UserCurrentGetRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("error getting the current user: %d", code)
+	}
+
+	loc := localTime()
+	result, code, err := api.TimeEntryAllGet(redmine.TimeEntryAllGetRequest{
+		Filters: redmine.TimeEntryGetRequestFiltersInit().
+			UserIDSet(user.ID).
+			SpentOnSet(original.Start.In(loc).Format("2006-01-02"), original.End.In(loc).Format("2006-01-02")),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("error getting the time entries: %d", code)
+	}
+
+	state := newSyncState("S2R", "#", originalParts)
+	matches := matchTimeEntries(originalParts, result.TimeEntries)
+	updates := []RedmineUpdate{}
+	missing := 0
+	for i, part := range originalParts {
+		if matches[i] < 0 {
+			// parts of partially synced intervals may not be pushed yet
+			if state.synced(part) {
+				missing++
+			}
+			continue
+		}
+		entry := result.TimeEntries[matches[i]]
+
+		update := redmine.TimeEntryUpdateObject{}
+		comment, _, err := st.render(editedParts[i], "#", issues)
+		if err != nil {
+			return nil, err
+		}
+		if comment != entry.Comments {
+			update.Comments = &comment
+		}
+		if editedParts[i].ActivityID != part.ActivityID {
+			activityID, err := strconv.ParseInt(editedParts[i].ActivityID, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			update.ActivityID = &activityID
+		}
+		issueID, err := rl.getIssueID(editedParts[i].IssueIDs)
+		if err != nil {
+			return nil, err
+		}
+		if issueID == 0 {
+			return nil, fmt.Errorf("interval %s has no Redmine issue anymore, please delete its time entries by hand", original.ID)
+		}
+		if issueID != entry.Issue.ID {
+			update.IssueID = &issueID
+		}
+		if update != (redmine.TimeEntryUpdateObject{}) {
+			updates = append(updates, RedmineUpdate{ID: entry.ID, Update: update})
+		}
+	}
+
+	if missing > 0 {
+		return nil, fmt.Errorf("no Redmine time entry found for %d parts of interval %s, please update them by hand", missing, original.ID)
+	}
+	return updates, nil
+}
+
+// propagate writes the updates of the Redmine time entries of an interval.
+func (rl RedmineLogger) propagate(intervalID string, updates []RedmineUpdate) error {
+	api, err := rl.getApi()
+	if err != nil {
+		return err
+	}
+
+	for _, update := range updates {
+		code, err := api.TimeEntryUpdate(update.ID, redmine.TimeEntryUpdate{TimeEntry: update.Update})
+		if err != nil {
+			return err
+		}
+		if code != 204 {
+			return fmt.Errorf("error updating time entry %d: %d", update.ID, code)
+		}
+	}
+	log.Printf("Updated %d Redmine time entries of interval %s", len(updates), intervalID)
+
+	return nil
+}
+
+func editCommand() cli.Command {
+	return cli.Command{
+		Name:      "edit",
+		Usage:     "Change the comment, activity or issue of intervals in timewarrior.",
+		ArgsUsage: "[id]...",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "comment",
+				Usage: "The new comment.",
+			},
+			&cli.StringFlag{
+				Name:  "activity",
+				Usage: "The new Redmine activity ID.",
+			},
+			&cli.StringFlag{
+				Name:  "issue",
+				Usage: "The new issue, replacing the issue of the same tracker.",
+			},
+			&cli.StringFlag{
+				Name:  "range",
+				Usage: "Edit all intervals in the time range instead of the given IDs. Valid ranges are 'all', 'month', 'week', and 'day'.",
+			},
+			&cli.StringFlag{
+				Name:  "has-issue",
+				Usage: "Only edit the intervals of the range booked on this issue.",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Also edit synced intervals, without changing Redmine or JIRA.",
+			},
+			&cli.BoolFlag{
+				Name:  "propagate",
+				Usage: "Also edit synced intervals and update their Redmine time entries.",
+			},
+		}, redmineFlags()...),
+		Action: func(ctx *cli.Context) error {
+			aliases, err := aliasMap()
+			if err != nil {
+				return err
+			}
+
+			edit := Edit{
+				Comment:  ctx.String("comment"),
+				Activity: ctx.String("activity"),
+			}
+			if ctx.String("issue") != "" {
+				issueID, err := normalizeIssue(ctx.String("issue"))
+				if alias, ok := aliases[ctx.String("issue")]; ok {
+					issueID, err = alias.Issue, nil
+				}
+				if err != nil {
+					return err
+				}
+				edit.Issue = issueID
+			}
+			if edit == (Edit{}) {
+				return fmt.Errorf("please give a --comment, --activity or --issue")
+			}
+
+			IDs := map[string]bool{}
+			for _, ID := range ctx.Args() {
+				IDs[strings.TrimPrefix(ID, "@")] = true
+			}
+			time_range := ctx.String("range")
+			if len(IDs) == 0 && time_range == "" {
+				return fmt.Errorf("please give the IDs of the intervals or a --range")
+			}
+			if len(IDs) > 0 && time_range != "" {
+				return fmt.Errorf("please give either IDs or a --range")
+			}
+			if time_range == "" {
+				time_range = "all"
+			}
+			if time_range != "all" && time_range != "month" && time_range != "week" && time_range != "day" {
+				return fmt.Errorf("invalid time range, please use 'all', 'month', 'week', or 'day'")
+			}
+
+			hasIssue := ""
+			if ctx.String("has-issue") != "" {
+				issueID, err := normalizeIssue(ctx.String("has-issue"))
+				if alias, ok := aliases[ctx.String("has-issue")]; ok {
+					issueID, err = alias.Issue, nil
+				}
+				if err != nil {
+					return err
+				}
+				hasIssue = issueID
+			}
+
+			// the tags belong to the intervals as stored in timewarrior
+			el := EntryList{Aliases: aliases, raw: true}
+			if err := el.fromTimeWarrior(time_range); err != nil {
+				return err
+			}
+
+			entries := []TimeEntry{}
+			for _, entry := range el.Entries {
+				if len(IDs) > 0 && !IDs[entry.ID] {
+					continue
+				}
+				if hasIssue != "" && entry.issueTagOf(hasIssue, aliases) == "" {
+					continue
+				}
+				entries = append(entries, entry)
+			}
+			if len(entries) == 0 {
+				return fmt.Errorf("no matching intervals found")
+			}
+
+			// check all intervals first, so nothing is edited halfway
+			for _, entry := range entries {
+				if !entry.hasSyncMarker("S2R") && !entry.hasSyncMarker("S2J") {
+					continue
+				}
+				if !ctx.Bool("force") && !ctx.Bool("propagate") {
+					return fmt.Errorf("interval %s is already synced, use --propagate to update Redmine as well or --force to only edit timewarrior", entry.ID)
+				}
+				if ctx.Bool("propagate") && entry.hasSyncMarker("S2J") && !ctx.Bool("force") {
+					return fmt.Errorf("interval %s is synced to JIRA, where worklogs can not be updated; use --force and change the worklog by hand", entry.ID)
+				}
+			}
+
+			rl := RedmineLogger{
+				APIKey:       ctx.String("redmine-api-token"),
+				URL:          ctx.String("redmine-url"),
+				TicketPrefix: "#",
+			}
+
+			// the comments are updated as they are pushed
+			config, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			var resolver *IssueResolver
+			if ctx.Bool("propagate") && config.Templates.Redmine.usesIssues() {
+				resolver, err = newIssueResolver(ctx)
+				if err != nil {
+					return err
+				}
+			}
+
+			// the Redmine time entries are found before timewarrior is changed
+			updates := map[string][]RedmineUpdate{}
+			for _, entry := range entries {
+				edited, err := entry.edited(edit)
+				if err != nil {
+					return err
+				}
+				if !ctx.Bool("propagate") || !entry.hasSyncMarker("S2R") {
+					continue
+				}
+
+				var issues map[string]IssueInfo
+				if resolver != nil {
+					issues = resolver.ResolveAll([]TimeEntry{edited})
+				}
+				updates[entry.ID], err = rl.propagation(entry, edited, config.Templates.sink(rl.TicketPrefix), issues)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, entry := range entries {
+				if err := entry.apply(edit, aliases); err != nil {
+					return err
+				}
+
+				if ctx.Bool("propagate") && entry.hasSyncMarker("S2R") {
+					if err := rl.propagate(entry.ID, updates[entry.ID]); err != nil {
+						return err
+					}
+				}
+				if entry.hasSyncMarker("S2J") {
+					log.Printf("Interval %s is synced to JIRA, please change the worklog by hand", entry.ID)
+				}
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+func TestMatchTimeEntries(t *testing.T) {
+	// 22:00 to 02:00 local time, split 75:25 between two issues
+	entry, err := parse(TimeWarriorEntry{
+		ID:    1,
+		Start: "20261005T200000Z",
+		End:   "20261006T000000Z",
+		Tags:  []string{"R_100:3", "R_200:1", "A_9", "fixed the login"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	parts, err := pushedParts(*entry)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(parts) != 4 {
+		t.Fatalf("Expected 4 parts, got %d", len(parts))
+	}
+
+	// the comments are rendered by a template, so they do not match
+	entries := []redmine.TimeEntryObject{
+		{ID: 11, Issue: redmine.TimeEntryIssueObject{ID: 200}, Hours: 0.5, SpentOn: "2026-10-06", Comments: "#200: fixed the login"},
+		{ID: 12, Issue: redmine.TimeEntryIssueObject{ID: 100}, Hours: 1.5, SpentOn: "2026-10-05", Comments: "#100: fixed the login"},
+		{ID: 13, Issue: redmine.TimeEntryIssueObject{ID: 100}, Hours: 1.5, SpentOn: "2026-10-06", Comments: "#100: fixed the login"},
+		{ID: 14, Issue: redmine.TimeEntryIssueObject{ID: 300}, Hours: 0.5, SpentOn: "2026-10-05", Comments: "other work"},
+	}
+
+	matches := matchTimeEntries(parts, entries)
+	expected := []int{1, -1, 2, 0}
+	for i := range expected {
+		if matches[i] != expected[i] {
+			t.Errorf("Expected part %d (%s) to match %d, got %d", i, parts[i].partKey("#"), expected[i], matches[i])
+		}
+	}
+}
+
+func TestTimeEntryEdited(t *testing.T) {
+	entry, err := parse(TimeWarriorEntry{
+		ID:    1,
+		Start: "20261005T080000Z",
+		End:   "20261005T100000Z",
+		Tags:  []string{"R_100:70", "R_200:30", "A_9", "fixed the login"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	// several Redmine issues can not be replaced by one
+	if _, err := entry.edited(Edit{Issue: "#300"}); err == nil {
+		t.Errorf("Expected an error for several issues")
+	}
+
+	edited, err := entry.edited(Edit{Comment: "reviewed the login", Activity: "12"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if edited.Comment != "reviewed the login" || edited.ActivityID != "12" {
+		t.Errorf("Expected the new comment and activity, got %q and %s", edited.Comment, edited.ActivityID)
+	}
+
+	// the original keeps its weights, so its pushed parts are found
+	edited.Weights["#100"] = 1
+	if entry.Weights["#100"] != 70 {
+		t.Errorf("Expected the weights of the original to stay, got %v", entry.Weights)
+	}
+	if parts, err := pushedParts(*entry); err != nil || len(parts) != 2 {
+		t.Errorf("Expected 2 parts of the original, got %d (%v)", len(parts), err)
+	}
+
+	single, err := parse(TimeWarriorEntry{ID: 2, Start: "20261005T080000Z", End: "20261005T100000Z", Tags: []string{"R_100:2", "J_5"}}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	edited, err = single.edited(Edit{Issue: "#300"})
+	if err != nil || edited.IssueIDs[0] != "#300" || edited.Weights["#300"] != 2 || single.IssueIDs[0] != "#100" {
+		t.Errorf("Expected #300 with the weight of #100, got %v %v (%v)", edited.IssueIDs, edited.Weights, err)
+	}
+}
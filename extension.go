@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ExtensionInput is what timewarrior passes to report extensions on stdin:
+// the configuration as `key: value` lines, a blank line and the intervals
+// as JSON.
+type ExtensionInput struct {
+	Config    map[string]string
+	Intervals []TimeWarriorEntry
+}
+
+// extension holds the input when worklogger runs as a timewarrior extension.
+// The intervals then replace the ones read from the data files, as they are
+// already filtered by timewarrior.
+var extension *ExtensionInput
+
+// errNoExtensionInput is returned when stdin does not start with the
+// configuration of timewarrior, e.g. when run from cron.
+var errNoExtensionInput = errors.New("no timewarrior extension input")
+
+// fromTimewarrior reports whether timewarrior wrote the configuration, which
+// always holds the temporary `temp.db` and `temp.report.*` settings.
+func fromTimewarrior(config map[string]string) bool {
+	for key := range config {
+		if key == "temp.db" || strings.HasPrefix(key, "temp.report.") {
+			return true
+		}
+	}
+	return false
+}
+
+func parseExtensionInput(r io.Reader) (*ExtensionInput, error) {
+	input := &ExtensionInput{Config: map[string]string{}}
+	reader := bufio.NewReader(r)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if !fromTimewarrior(input.Config) {
+				return nil, errNoExtensionInput
+			}
+			return nil, fmt.Errorf("no intervals found after the configuration")
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			key, value, ok = strings.Cut(line, ":")
+		}
+		if !ok {
+			if !fromTimewarrior(input.Config) {
+				return nil, errNoExtensionInput
+			}
+			return nil, fmt.Errorf("invalid configuration line %q", line)
+		}
+		input.Config[key] = value
+	}
+	if !fromTimewarrior(input.Config) {
+		return nil, errNoExtensionInput
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &input.Intervals); err != nil {
+		return nil, fmt.Errorf("invalid intervals: %s", err)
+	}
+
+	return input, nil
+}
+
+// extensionArgs returns the command line to run for the extension, taken
+// from `reports.worklogger.command`, e.g. `report --by project`. It defaults
+// to `list`.
+func (input *ExtensionInput) extensionArgs() []string {
+	command := strings.TrimSpace(input.Config["reports.worklogger.command"])
+	if command == "" {
+		return []string{"list"}
+	}
+	return strings.Fields(command)
+}
+
+// runsAsExtension reports whether worklogger may be started by timewarrior,
+// which runs extensions without arguments and the input on stdin. The input
+// tells whether it was.
+func runsAsExtension() bool {
+	if len(os.Args) > 1 {
+		return false
+	}
+
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice == 0
+}
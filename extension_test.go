@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExtensionInput(t *testing.T) {
+	input := `temp.report.start: 20261005T000000Z
+temp.report.end: 20261012T000000Z
+reports.worklogger.command: report --by project
+verbose: on
+
+[
+{"id":2,"start":"20261005T080000Z","end":"20261005T100000Z","tags":["R_123","fixed the login"]},
+{"id":1,"start":"20261006T080000Z","tags":["R_124"],"annotation":"still running"}
+]
+`
+
+	extension, err := parseExtensionInput(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	if extension.Config["temp.report.start"] != "20261005T000000Z" || extension.Config["verbose"] != "on" {
+		t.Errorf("Expected the configuration to be parsed, got %v", extension.Config)
+	}
+	if args := extension.extensionArgs(); strings.Join(args, " ") != "report --by project" {
+		t.Errorf("Expected the configured command, got %v", args)
+	}
+	if len(extension.Intervals) != 2 || extension.Intervals[1].Annotation != "still running" {
+		t.Fatalf("Expected 2 intervals, got %+v", extension.Intervals)
+	}
+
+	el := EntryList{}
+	if err := el.fromIntervals(extension.Intervals); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(el.Entries) != 1 || el.Entries[0].ID != "2" || el.Entries[0].Comment != "fixed the login" {
+		t.Errorf("Expected the closed interval, got %+v", el.Entries)
+	}
+
+	if _, err := parseExtensionInput(strings.NewReader("temp.db: /tmp/timew\nverbose: on\n")); err == nil || err == errNoExtensionInput {
+		t.Errorf("Expected an error without intervals, got %v", err)
+	}
+	// e.g. started from cron with a pipe or /dev/null as stdin
+	for _, input := range []string{"", "some output\n", "verbose: on\n\n[]\n"} {
+		if _, err := parseExtensionInput(strings.NewReader(input)); err != errNoExtensionInput {
+			t.Errorf("Expected no extension input for %q, got %v", input, err)
+		}
+	}
+	if args := (&ExtensionInput{}).extensionArgs(); len(args) != 1 || args[0] != "list" {
+		t.Errorf("Expected list by default, got %v", args)
+	}
+}
+
+func TestFromOwnRangeAsExtension(t *testing.T) {
+	extension = &ExtensionInput{Config: map[string]string{"temp.db": "/tmp/timew"}}
+	defer func() { extension = nil }()
+
+	// timewarrior passes only the intervals of its range
+	el := EntryList{}
+	if err := el.fromOwnRange("balance", time.Now().AddDate(0, -1, 0), time.Now()); err == nil {
+		t.Errorf("Expected an error as extension")
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+type HolidayConfig struct {
+	// Country is an ISO 3166 code, e.g. `DE` or `AT`.
+	Country string `json:"country"`
+	// Region is the subdivision code, e.g. `BY` for Bavaria.
+	Region string `json:"region"`
+}
+
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// holidayRule describes a public holiday. An empty list of regions means the
+// holiday applies to the whole country. Since is the first year the holiday
+// was observed, zero for ever.
+type holidayRule struct {
+	name    string
+	date    func(year int, easter time.Time) time.Time
+	regions []string
+	since   int
+}
+
+func fixed(month time.Month, day int) func(int, time.Time) time.Time {
+	return func(year int, _ time.Time) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func easterOffset(days int) func(int, time.Time) time.Time {
+	return func(_ int, easter time.Time) time.Time {
+		return easter.AddDate(0, 0, days)
+	}
+}
+
+// repentanceDay is the wednesday before the 23rd of november.
+func repentanceDay(year int, _ time.Time) time.Time {
+	day := time.Date(year, time.November, 22, 0, 0, 0, 0, time.UTC)
+	for day.Weekday() != time.Wednesday {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+var holidayRules = map[string][]holidayRule{
+	"DE": {
+		{name: "Neujahr", date: fixed(time.January, 1)},
+		{name: "Heilige Drei Könige", date: fixed(time.January, 6), regions: []string{"BW", "BY", "ST"}},
+		{name: "Internationaler Frauentag", date: fixed(time.March, 8), regions: []string{"BE"}, since: 2019},
+		{name: "Internationaler Frauentag", date: fixed(time.March, 8), regions: []string{"MV"}, since: 2023},
+		{name: "Karfreitag", date: easterOffset(-2)},
+		{name: "Ostersonntag", date: easterOffset(0), regions: []string{"BB"}},
+		{name: "Ostermontag", date: easterOffset(1)},
+		{name: "Tag der Arbeit", date: fixed(time.May, 1)},
+		{name: "Christi Himmelfahrt", date: easterOffset(39)},
+		{name: "Pfingstsonntag", date: easterOffset(49), regions: []string{"BB"}},
+		{name: "Pfingstmontag", date: easterOffset(50)},
+		{name: "Fronleichnam", date: easterOffset(60), regions: []string{"BW", "BY", "HE", "NW", "RP", "SL"}},
+		{name: "Mariä Himmelfahrt", date: fixed(time.August, 15), regions: []string{"SL"}},
+		{name: "Weltkindertag", date: fixed(time.September, 20), regions: []string{"TH"}, since: 2019},
+		{name: "Tag der Deutschen Einheit", date: fixed(time.October, 3)},
+		{name: "Reformationstag", date: fixed(time.October, 31), regions: []string{"BB", "MV", "SN", "ST", "TH"}},
+		{name: "Reformationstag", date: fixed(time.October, 31), regions: []string{"HB", "HH", "NI", "SH"}, since: 2018},
+		{name: "Allerheiligen", date: fixed(time.November, 1), regions: []string{"BW", "BY", "NW", "RP", "SL"}},
+		{name: "Buß- und Bettag", date: repentanceDay, regions: []string{"SN"}},
+		{name: "1. Weihnachtstag", date: fixed(time.December, 25)},
+		{name: "2. Weihnachtstag", date: fixed(time.December, 26)},
+	},
+	"AT": {
+		{name: "Neujahr", date: fixed(time.January, 1)},
+		{name: "Heilige Drei Könige", date: fixed(time.January, 6)},
+		{name: "Ostermontag", date: easterOffset(1)},
+		{name: "Staatsfeiertag", date: fixed(time.May, 1)},
+		{name: "Christi Himmelfahrt", date: easterOffset(39)},
+		{name: "Pfingstmontag", date: easterOffset(50)},
+		{name: "Fronleichnam", date: easterOffset(60)},
+		{name: "Mariä Himmelfahrt", date: fixed(time.August, 15)},
+		{name: "Nationalfeiertag", date: fixed(time.October, 26)},
+		{name: "Allerheiligen", date: fixed(time.November, 1)},
+		{name: "Mariä Empfängnis", date: fixed(time.December, 8)},
+		{name: "Christtag", date: fixed(time.December, 25)},
+		{name: "Stefanitag", date: fixed(time.December, 26)},
+	},
+}
+
+// easterSunday computes the date of easter sunday in the gregorian calendar
+// with the anonymous gregorian algorithm (Meeus/Jones/Butcher).
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func holidays(country, region string, year int) ([]Holiday, error) {
+	rules, ok := holidayRules[country]
+	if !ok {
+		return nil, fmt.Errorf("no holidays known for country %q", country)
+	}
+
+	easter := easterSunday(year)
+	result := []Holiday{}
+	for _, rule := range rules {
+		if rule.since > year {
+			continue
+		}
+
+		applies := len(rule.regions) == 0
+		for _, r := range rule.regions {
+			if r == region {
+				applies = true
+			}
+		}
+		if !applies {
+			continue
+		}
+
+		result = append(result, Holiday{Date: rule.date(year, easter), Name: rule.name})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Date.Before(result[j].Date)
+	})
+
+	return result, nil
+}
+
+// HolidayCalendar caches the holidays per year of the configured region.
+type HolidayCalendar struct {
+	Country string
+	Region  string
+	years   map[int]map[string]string
+}
+
+func newHolidayCalendar(config HolidayConfig) (*HolidayCalendar, error) {
+	if config.Country == "" {
+		return nil, nil
+	}
+	if _, ok := holidayRules[config.Country]; !ok {
+		return nil, fmt.Errorf("no holidays known for country %q", config.Country)
+	}
+
+	return &HolidayCalendar{
+		Country: config.Country,
+		Region:  config.Region,
+		years:   map[int]map[string]string{},
+	}, nil
+}
+
+// Holiday returns the name of the holiday on the given day, if there is one.
+func (hc *HolidayCalendar) Holiday(day time.Time) (string, bool) {
+	if hc == nil {
+		return "", false
+	}
+
+	dates, ok := hc.years[day.Year()]
+	if !ok {
+		list, err := holidays(hc.Country, hc.Region, day.Year())
+		if err != nil {
+			return "", false
+		}
+
+		dates = map[string]string{}
+		for _, holiday := range list {
+			dates[holiday.Date.Format("2006-01-02")] = holiday.Name
+		}
+		hc.years[day.Year()] = dates
+	}
+
+	name, ok := dates[day.Format("2006-01-02")]
+	return name, ok
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEasterSunday(t *testing.T) {
+	tests := map[int]string{
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2026: "2026-04-05",
+		2038: "2038-04-25",
+	}
+
+	for year, expected := range tests {
+		if easter := easterSunday(year).Format("2006-01-02"); easter != expected {
+			t.Errorf("Expected easter %s for %d, got %s", expected, year, easter)
+		}
+	}
+}
+
+func TestHolidayCalendarRegions(t *testing.T) {
+	corpusChristi := time.Date(2026, time.June, 4, 0, 0, 0, 0, time.UTC)
+	repentanceDay := time.Date(2026, time.November, 18, 0, 0, 0, 0, time.UTC)
+
+	bavaria, err := newHolidayCalendar(HolidayConfig{Country: "DE", Region: "BY"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	saxony, err := newHolidayCalendar(HolidayConfig{Country: "DE", Region: "SN"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	if name, ok := bavaria.Holiday(corpusChristi); !ok || name != "Fronleichnam" {
+		t.Errorf("Expected Fronleichnam in BY, got %q", name)
+	}
+	if _, ok := saxony.Holiday(corpusChristi); ok {
+		t.Errorf("Expected no holiday in SN on Fronleichnam")
+	}
+	if _, ok := saxony.Holiday(repentanceDay); !ok {
+		t.Errorf("Expected Buß- und Bettag in SN")
+	}
+	if _, ok := bavaria.Holiday(repentanceDay); ok {
+		t.Errorf("Expected no holiday in BY on Buß- und Bettag")
+	}
+
+	if _, err := newHolidayCalendar(HolidayConfig{Country: "XX"}); err == nil {
+		t.Errorf("Expected error for unknown country")
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	jira "github.com/andygrunwald/go-jira/v2/onpremise"
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/urfave/cli"
+)
+
+const issueCacheTTL = 24 * time.Hour
+
+type IssueInfo struct {
+	ID        string
+	Subject   string
+	Project   string
+	ProjectID string
+	Status    string
+	Fetched   time.Time
+}
+
+type IssueCache struct {
+	path   string
+	Issues map[string]IssueInfo
+}
+
+func loadIssueCache() (*IssueCache, error) {
+	path, err := xdg.CacheFile("worklogger/issues.json")
+	if err != nil {
+		return nil, err
+	}
+
+	cache := &IssueCache{
+		path:   path,
+		Issues: map[string]IssueInfo{},
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &cache.Issues); err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+func (c *IssueCache) save() error {
+	data, err := json.MarshalIndent(c.Issues, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, data, 0o644)
+}
+
+// IssueResolver looks up issue details in the local cache and falls back to
+// Redmine or JIRA, depending on the prefix of the issue ID.
+type IssueResolver struct {
+	Redmine *RedmineLogger
+	Jira    *JiraLogger
+	Offline bool
+
+	cache      *IssueCache
+	jiraClient *jira.Client
+}
+
+func newIssueResolver(ctx *cli.Context) (*IssueResolver, error) {
+	cache, err := loadIssueCache()
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &IssueResolver{
+		Offline: ctx.Bool("offline"),
+		cache:   cache,
+	}
+
+	if ctx.String("redmine-url") != "" && ctx.String("redmine-api-token") != "" {
+		resolver.Redmine = &RedmineLogger{
+			APIKey:       ctx.String("redmine-api-token"),
+			URL:          ctx.String("redmine-url"),
+			TicketPrefix: "#",
+		}
+	}
+
+	if ctx.String("jira-url") != "" && ctx.String("jira-api-token") != "" {
+		resolver.Jira = &JiraLogger{
+			Username:     ctx.String("jira-username"),
+			Password:     ctx.String("jira-api-token"),
+			URL:          ctx.String("jira-url"),
+			TicketPrefix: "PIM-",
+		}
+	}
+
+	return resolver, nil
+}
+
+func (r *IssueResolver) Resolve(issueID string) (IssueInfo, error) {
+	cached, ok := r.cache.Issues[issueID]
+	if ok && (r.Offline || time.Since(cached.Fetched) < issueCacheTTL) {
+		return cached, nil
+	}
+	if r.Offline {
+		return IssueInfo{ID: issueID}, fmt.Errorf("issue %s is not cached", issueID)
+	}
+
+	var info IssueInfo
+	var err error
+	switch {
+	case r.Redmine != nil && strings.HasPrefix(issueID, r.Redmine.TicketPrefix):
+		info, err = r.resolveRedmine(issueID)
+	case r.Jira != nil && hasIssuePrefix(issueID, r.Jira.TicketPrefix):
+		info, err = r.resolveJira(issueID)
+	default:
+		err = fmt.Errorf("no tracker configured for issue %s", issueID)
+	}
+	if err != nil {
+		if ok {
+			// an outdated cache entry is better than nothing
+			return cached, nil
+		}
+		return IssueInfo{ID: issueID}, err
+	}
+
+	info.Fetched = time.Now()
+	r.cache.Issues[issueID] = info
+
+	return info, nil
+}
+
+func (r *IssueResolver) resolveRedmine(issueID string) (IssueInfo, error) {
+	ID, err := strconv.ParseInt(strings.TrimPrefix(issueID, r.Redmine.TicketPrefix), 10, 64)
+	if err != nil {
+		return IssueInfo{}, err
+	}
+
+	api, err := r.Redmine.getApi()
+	if err != nil {
+		return IssueInfo{}, err
+	}
+
+	issue, code, err := api.IssueSingleGet(ID, redmine.IssueSingleGetRequest{})
+	if err != nil {
+		return IssueInfo{}, err
+	}
+	if code != 200 {
+		return IssueInfo{}, fmt.Errorf("unexpected code on %s: %d", issueID, code)
+	}
+
+	return IssueInfo{
+		ID:        issueID,
+		Subject:   issue.Subject,
+		Project:   issue.Project.Name,
+		ProjectID: strconv.FormatInt(issue.Project.ID, 10),
+		Status:    issue.Status.Name,
+	}, nil
+}
+
+func (r *IssueResolver) resolveJira(issueID string) (IssueInfo, error) {
+	if r.jiraClient == nil {
+		client, err := r.Jira.getJiraClient()
+		if err != nil {
+			return IssueInfo{}, err
+		}
+		r.jiraClient = client
+	}
+
+	issue, err := r.Jira.getIssue(r.jiraClient, issueID)
+	if err != nil {
+		return IssueInfo{}, err
+	}
+
+	info := IssueInfo{ID: issueID}
+	if issue.Fields != nil {
+		info.Subject = issue.Fields.Summary
+		info.Project = issue.Fields.Project.Name
+		info.ProjectID = issue.Fields.Project.Key
+		if issue.Fields.Status != nil {
+			info.Status = issue.Fields.Status.Name
+		}
+	}
+
+	return info, nil
+}
+
+// ResolveAll resolves every issue referenced by the entries. Issues which can
+// not be resolved are logged and left out of the result.
+func (r *IssueResolver) ResolveAll(entries []TimeEntry) map[string]IssueInfo {
+	issues := map[string]IssueInfo{}
+	for _, entry := range entries {
+		for _, issueID := range entry.IssueIDs {
+			if _, ok := issues[issueID]; ok {
+				continue
+			}
+
+			info, err := r.Resolve(issueID)
+			if err != nil {
+				log.Printf("Could not resolve %s: %s", issueID, err)
+				continue
+			}
+			issues[issueID] = info
+		}
+	}
+
+	if err := r.Save(); err != nil {
+		log.Printf("Could not save issue cache: %s", err)
+	}
+
+	return issues
+}
+
+// Save writes the resolved issues to the local cache.
+func (r *IssueResolver) Save() error {
+	return r.cache.save()
+}
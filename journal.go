@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+const journalLimit = 200
+
+// JournalChange is a single change of timewarrior. Intervals are identified
+// by a point in time they contain, as their IDs shift when intervals are added.
+type JournalChange struct {
+	Action string    `json:"action"`
+	At     time.Time `json:"at"`
+	// Tags holds the changed tags.
+	Tags []string `json:"tags,omitempty"`
+	// OldAnnotation and Annotation hold the old and new annotation.
+	OldAnnotation string `json:"old_annotation,omitempty"`
+	Annotation    string `json:"annotation,omitempty"`
+	// From and To hold the old and new time of a modification, or the
+	// tracked interval.
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+	// Pending marks a change which was recorded, but timewarrior failed or
+	// the command stopped before it was done. It may not have been made.
+	Pending bool `json:"pending,omitempty"`
+}
+
+// timeRef returns a reference to the time for the fields of a change.
+func timeRef(t time.Time) *time.Time {
+	return &t
+}
+
+func (c JournalChange) String() string {
+	loc := localTime()
+	format := "2006-01-02 15:04:05"
+	pending := ""
+	if c.Pending {
+		pending = " (pending)"
+	}
+	switch c.Action {
+	case "tag", "untag":
+		return fmt.Sprintf("%s %s: %s%s", c.Action, c.At.In(loc).Format(format), strings.Join(c.Tags, ", "), pending)
+	case "annotate":
+		return fmt.Sprintf("annotate %s: %q -> %q%s", c.At.In(loc).Format(format), c.OldAnnotation, c.Annotation, pending)
+	case "track":
+		return fmt.Sprintf("track %s - %s%s", c.From.In(loc).Format(format), c.To.In(loc).Format(format), pending)
+	case "continue":
+		return fmt.Sprintf("continue %s at %s%s", c.From.In(loc).Format(format), c.At.In(loc).Format(format), pending)
+	}
+	return fmt.Sprintf("%s %s -> %s%s", c.Action, c.From.In(loc).Format(format), c.To.In(loc).Format(format), pending)
+}
+
+// Operation is one run of a command which changed timewarrior.
+type Operation struct {
+	ID       int             `json:"id"`
+	Time     time.Time       `json:"time"`
+	Command  string          `json:"command"`
+	Changes  []JournalChange `json:"changes"`
+	Restored bool            `json:"restored,omitempty"`
+}
+
+type Journal struct {
+	path       string
+	Operations []Operation
+	current    *Operation
+}
+
+// journal records the changes of the running command. It is nil when no
+// command is running, e.g. in tests.
+var journal *Journal
+
+func loadJournal() (*Journal, error) {
+	path, err := xdg.DataFile("worklogger/journal.json")
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Journal{path: path}
+	j.Operations, err = j.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+func (j *Journal) load() ([]Operation, error) {
+	data, err := os.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	operations := []Operation{}
+	if err := json.Unmarshal(data, &operations); err != nil {
+		return nil, fmt.Errorf("error reading %s: %s", j.path, err)
+	}
+
+	return operations, nil
+}
+
+func (j *Journal) save() error {
+	if len(j.Operations) > journalLimit {
+		j.Operations = j.Operations[len(j.Operations)-journalLimit:]
+	}
+
+	data, err := json.MarshalIndent(j.Operations, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(j.path, data, 0o644)
+}
+
+// start begins a new operation, which is only stored once it has changes.
+func (j *Journal) start(command string) {
+	ID := 1
+	if len(j.Operations) > 0 {
+		ID = j.Operations[len(j.Operations)-1].ID + 1
+	}
+	j.current = &Operation{ID: ID, Time: time.Now(), Command: command}
+}
+
+func (j *Journal) operation(ID int) (*Operation, error) {
+	for i := range j.Operations {
+		if j.Operations[i].ID == ID {
+			return &j.Operations[i], nil
+		}
+	}
+	return nil, fmt.Errorf("operation %d not found in the history", ID)
+}
+
+// record adds a change to the running operation before timewarrior is
+// changed. The journal is written right away, so the change is known even if
+// the command stops halfway. The returned function marks it as done.
+func record(change JournalChange) func() {
+	if journal == nil || journal.current == nil {
+		return func() {}
+	}
+
+	if len(journal.current.Changes) == 0 {
+		journal.Operations = append(journal.Operations, *journal.current)
+	}
+	change.Pending = true
+	journal.current.Changes = append(journal.current.Changes, change)
+	index := len(journal.current.Changes) - 1
+	journal.update()
+
+	current := journal.current
+	return func() {
+		current.Changes[index].Pending = false
+		if journal.current == current {
+			journal.update()
+		}
+	}
+}
+
+// update stores the running operation and writes the journal.
+func (j *Journal) update() {
+	j.Operations[len(j.Operations)-1] = *j.current
+	if err := j.save(); err != nil {
+		log.Printf("Could not write the journal: %s", err)
+	}
+}
+
+// recordTags records the tags which tagging, or untagging, an interval with
+// the current tags actually changes. A revert then leaves the tags alone
+// which were there before.
+func recordTags(action string, at time.Time, current, tags []string) func() {
+	changed := []string{}
+	for _, tag := range tags {
+		present := false
+		for _, t := range current {
+			present = present || t == tag
+		}
+		if present == (action == "untag") {
+			changed = append(changed, tag)
+		}
+	}
+	if len(changed) == 0 {
+		return func() {}
+	}
+	return record(JournalChange{Action: action, At: at, Tags: changed})
+}
+
+// intervalAt finds the timewarrior interval containing the point in time.
+func intervalAt(at time.Time) (*TimeEntry, error) {
+	el := EntryList{raw: true, running: true}
+	if err := el.fromTimeWarriorData(at, at.Add(time.Second)); err != nil {
+		return nil, err
+	}
+	for _, entry := range el.Entries {
+		if !entry.Start.After(at) && entry.End.After(at) {
+			return &entry, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no interval found at %s", at.In(localTime()).Format("2006-01-02 15:04:05"))
+}
+
+// revert undoes a change in timewarrior.
+func (c JournalChange) revert() error {
+	switch c.Action {
+	case "tag", "untag":
+		entry, err := intervalAt(c.At)
+		if err != nil {
+			return err
+		}
+		if c.Action == "tag" {
+			return entry.retag(c.Tags, nil)
+		}
+		return entry.retag(nil, c.Tags)
+	case "annotate":
+		entry, err := intervalAt(c.At)
+		if err != nil {
+			return err
+		}
+		return entry.annotate(c.OldAnnotation)
+	case "modify start":
+		entry, err := intervalAt(*c.To)
+		if err != nil {
+			return err
+		}
+		return entry.modifyStart(*c.From)
+	case "modify end":
+		entry, err := intervalAt(c.At)
+		if err != nil {
+			return err
+		}
+		return entry.modifyEnd(*c.From)
+	case "track":
+		entry, err := intervalAt(*c.From)
+		if err != nil {
+			return err
+		}
+		if !entry.Start.Equal(*c.From) || !entry.End.Equal(*c.To) {
+			return fmt.Errorf("the tracked interval %s was changed in the meantime", c)
+		}
+		return timew("delete", "@"+entry.ID)
+	case "continue":
+		// the stopped interval and its continuation are joined again
+		entry, err := intervalAt(c.At)
+		if err != nil {
+			return err
+		}
+		if !entry.Start.Equal(c.At) {
+			return fmt.Errorf("the continued interval %s was changed in the meantime", c)
+		}
+		previous, err := intervalAt(c.At.Add(-time.Second))
+		if err != nil {
+			return err
+		}
+		return timew("join", "@"+previous.ID, "@"+entry.ID)
+	}
+
+	return fmt.Errorf("unknown action %q", c.Action)
+}
+
+func historyCommand() cli.Command {
+	return cli.Command{
+		Name:      "history",
+		Usage:     "Show the operations which changed timewarrior.",
+		ArgsUsage: "[operation]",
+		Action: func(ctx *cli.Context) error {
+			j, err := loadJournal()
+			if err != nil {
+				return err
+			}
+
+			if ctx.NArg() > 0 {
+				ID, err := strconv.Atoi(ctx.Args().First())
+				if err != nil {
+					return err
+				}
+				operation, err := j.operation(ID)
+				if err != nil {
+					return err
+				}
+				for _, change := range operation.Changes {
+					fmt.Println(change)
+				}
+				return nil
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"ID", "Time", "Command", "Changes", "Restored"})
+			table.SetAutoWrapText(false)
+			for _, operation := range j.Operations {
+				restored := ""
+				if operation.Restored {
+					restored = "yes"
+				}
+				table.Append([]string{
+					strconv.Itoa(operation.ID),
+					operation.Time.In(localTime()).Format("2006-01-02 15:04:05"),
+					operation.Command,
+					strconv.Itoa(len(operation.Changes)),
+					restored,
+				})
+			}
+			table.Render()
+
+			return nil
+		},
+	}
+}
+
+func restoreCommand() cli.Command {
+	return cli.Command{
+		Name:      "restore",
+		Usage:     "Undo the changes of an operation from the history.",
+		ArgsUsage: "<operation>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Restore an operation which was restored already.",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			ID, err := strconv.Atoi(ctx.Args().First())
+			if err != nil {
+				return fmt.Errorf("please give the ID of the operation, see `worklogger history`")
+			}
+
+			operation, err := journal.operation(ID)
+			if err != nil {
+				return err
+			}
+			if operation.Restored && !ctx.Bool("force") {
+				return fmt.Errorf("operation %d was restored already", ID)
+			}
+			changes := operation.Changes
+
+			// the changes are undone from the last to the first
+			for i := len(changes) - 1; i >= 0; i-- {
+				log.Printf("Reverting %s", changes[i])
+				if err := changes[i].revert(); err != nil {
+					if changes[i].Pending {
+						log.Printf("Skipping %s, which may not have been made: %s", changes[i], err)
+						continue
+					}
+					return fmt.Errorf("could not revert %s: %s", changes[i], err)
+				}
+			}
+
+			// the restore itself was recorded, which may have moved the slice
+			operation, err = journal.operation(ID)
+			if err != nil {
+				return err
+			}
+			operation.Restored = true
+			return journal.save()
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournalRecord(t *testing.T) {
+	journal = &Journal{path: filepath.Join(t.TempDir(), "journal.json")}
+	defer func() { journal = nil }()
+
+	journal.start("list")
+	if len(journal.Operations) != 0 {
+		t.Fatalf("Expected operations without changes not to be stored")
+	}
+
+	at := time.Date(2026, 10, 5, 8, 0, 0, 0, time.UTC)
+	journal.start("tag --tag S2R --issue #123")
+	record(JournalChange{Action: "tag", At: at, Tags: []string{"S2R"}})()
+	// timewarrior failed, so the change stays pending
+	record(JournalChange{Action: "untag", At: at, Tags: []string{"S2J"}})
+
+	journal.start("split")
+	record(JournalChange{Action: "track", At: at, From: timeRef(at), To: timeRef(at.Add(time.Hour))})()
+
+	stored, err := (&Journal{path: journal.path}).load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("Expected 2 operations, got %d", len(stored))
+	}
+	if stored[0].ID != 1 || stored[0].Command != "tag --tag S2R --issue #123" || len(stored[0].Changes) != 2 {
+		t.Errorf("Expected the tag operation with 2 changes, got %+v", stored[0])
+	}
+	if len(stored[0].Changes) == 2 && (stored[0].Changes[0].Pending || !stored[0].Changes[1].Pending) {
+		t.Errorf("Expected only the failed change to be pending, got %+v", stored[0].Changes)
+	}
+	if stored[0].Changes[0].From != nil || stored[0].Changes[0].To != nil {
+		t.Errorf("Expected no times for a tag change, got %+v", stored[0].Changes[0])
+	}
+	if stored[1].ID != 2 || len(stored[1].Changes) != 1 || stored[1].Changes[0].Pending {
+		t.Errorf("Expected the split operation with 1 done change, got %+v", stored[1])
+	}
+	if change := stored[1].Changes[0]; change.From == nil || !change.From.Equal(at) || change.To == nil || !change.To.Equal(at.Add(time.Hour)) {
+		t.Errorf("Expected the tracked interval, got %+v", change)
+	}
+}
+
+func TestRecordTags(t *testing.T) {
+	journal = &Journal{path: filepath.Join(t.TempDir(), "journal.json")}
+	defer func() { journal = nil }()
+
+	// S2R was there before, so reverting must not remove it
+	at := time.Date(2026, 10, 5, 8, 0, 0, 0, time.UTC)
+	current := []string{"R_123", "S2R"}
+	journal.start("tag --tag S2R --tag S2J")
+	recordTags("tag", at, current, []string{"S2R", "S2J"})
+	recordTags("untag", at, current, []string{"S2J"})
+	recordTags("tag", at, current, []string{"R_123"})
+
+	if len(journal.Operations) != 1 || len(journal.Operations[0].Changes) != 1 {
+		t.Fatalf("Expected one change, got %+v", journal.Operations)
+	}
+	change := journal.Operations[0].Changes[0]
+	if change.Action != "tag" || len(change.Tags) != 1 || change.Tags[0] != "S2J" {
+		t.Errorf("Expected only S2J to be recorded as tagged, got %+v", change)
+	}
+}
+
+func TestJournalAnnotate(t *testing.T) {
+	at := time.Date(2026, 10, 5, 8, 0, 0, 0, time.UTC)
+	change := JournalChange{Action: "annotate", At: at, OldAnnotation: "old, with comma", Annotation: "new"}
+	if s := change.String(); s != `annotate 2026-10-05 10:00:00: "old, with comma" -> "new"` {
+		t.Errorf("Expected the old and new annotation, got %s", s)
+	}
+}
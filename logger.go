@@ -53,6 +53,39 @@ func (rl RedmineLogger) getIssueID(issueIDs []string) (int64, error) {
 	return 0, nil
 }
 
+// getActivities returns the time entry activities enabled for a project.
+func (rl RedmineLogger) getActivities(projectID string) ([]Activity, error) {
+	api, err := rl.getApi()
+	if err != nil {
+		return nil, err
+	}
+
+	project, code, err := api.ProjectSingleGet(
+		projectID,
+		redmine.ProjectSingleGetRequest{
+			Includes: []redmine.ProjectInclude{redmine.ProjectIncludeTimeEntryActivities},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("error getting project %s: %d", projectID, code)
+	}
+
+	activities := []Activity{}
+	if project.TimeEntryActivities != nil {
+		for _, activity := range *project.TimeEntryActivities {
+			activities = append(activities, Activity{
+				ID:  strconv.FormatInt(activity.ID, 10),
+				Tag: activity.Name,
+			})
+		}
+	}
+
+	return activities, nil
+}
+
 func (rl RedmineLogger) Log(te TimeEntry) error {
 	ID, err := rl.getIssueID(te.IssueIDs)
 	if err != nil {
@@ -72,7 +105,7 @@ func (rl RedmineLogger) Log(te TimeEntry) error {
 		return err
 	}
 
-	date := te.Start.Format("2006-01-02")
+	date := te.Start.In(localTime()).Format("2006-01-02")
 
 	cte, code, err := api.TimeEntryCreate(
 		redmine.TimeEntryCreate{
@@ -95,10 +128,6 @@ func (rl RedmineLogger) Log(te TimeEntry) error {
 	fmt.Println(cte)
 	fmt.Println(code)
 
-	if code == http.StatusCreated {
-		te.mark("S2R")
-	}
-
 	return nil
 }
 
@@ -131,7 +160,7 @@ func (jl JiraLogger) getJiraClient() (*jira.Client, error) {
 
 func (jl JiraLogger) getIssueID(issueIDs []string) (string, error) {
 	for _, issueID := range issueIDs {
-		if issueID[:len(jl.TicketPrefix)] == jl.TicketPrefix {
+		if hasIssuePrefix(issueID, jl.TicketPrefix) {
 			return issueID, nil
 		}
 	}
@@ -177,7 +206,7 @@ func (jl JiraLogger) Log(te TimeEntry) error {
 	}
 
 	wl.ID = issue.ID
-	wl.StartDate = te.Start.Format("02/Jan/06 03:04 PM")
+	wl.StartDate = te.Start.In(localTime()).Format("02/Jan/06 03:04 PM")
 	wl.TimeLogged = fmt.Sprintf("%.2f", te.Hours.Hours())
 	wl.LogworkCategory = "cat1"
 	wl.Comment = te.Comment
@@ -220,7 +249,5 @@ func (jl JiraLogger) Log(te TimeEntry) error {
 		return fmt.Errorf("could not log work")
 	}
 
-	te.mark("S2J")
-
 	return nil
 }
@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/joho/godotenv"
@@ -18,6 +20,41 @@ var (
 	rl RedmineLogger
 )
 
+func redmineFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "redmine-api-token",
+			Usage: "The API key for Redmine.",
+			Value: os.Getenv("WL_REDMINE_API_TOKEN"),
+		},
+		&cli.StringFlag{
+			Name:  "redmine-url",
+			Usage: "The URL for Redmine.",
+			Value: os.Getenv("WL_REDMINE_URL"),
+		},
+	}
+}
+
+func jiraFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "jira-username",
+			Usage: "The username for JIRA.",
+			Value: os.Getenv("WL_JIRA_USERNAME"),
+		},
+		&cli.StringFlag{
+			Name:  "jira-api-token",
+			Usage: "The API token for JIRA.",
+			Value: os.Getenv("WL_JIRA_API_TOKEN"),
+		},
+		&cli.StringFlag{
+			Name:  "jira-url",
+			Usage: "The URL for JIRA.",
+			Value: os.Getenv("WL_JIRA_URL"),
+		},
+	}
+}
+
 func main() {
 	configFile, err := xdg.ConfigFile("worklogger/config.env")
 	if err != nil {
@@ -28,7 +65,40 @@ func main() {
 		log.Fatal("Error loading .env file")
 	}
 
-	el := EntryList{}
+	// comments are read with the intervals, before any command runs
+	config, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := config.Comments.check(); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.Templates.check(); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.Git.check(); err != nil {
+		log.Fatal(err)
+	}
+	comments = config.Comments
+
+	args := os.Args
+	if runsAsExtension() {
+		input, err := parseExtensionInput(os.Stdin)
+		switch {
+		case err == nil:
+			extension = input
+			args = append([]string{os.Args[0]}, extension.extensionArgs()...)
+		case err != errNoExtensionInput:
+			log.Fatal(err)
+		}
+	}
+
+	// changes of timewarrior are journaled, so they can be restored
+	journal, err = loadJournal()
+	if err != nil {
+		log.Fatal(err)
+	}
+	journal.start(strings.Join(args[1:], " "))
 
 	app := &cli.App{
 		Name:  "worklogger",
@@ -58,95 +128,452 @@ func main() {
 						Name:  "pending",
 						Usage: "Show time entries which are not yet synced to Redmine or JIRA.",
 					},
+					&cli.StringFlag{
+						Name:  "jira-username",
+						Usage: "The username for JIRA.",
+						Value: os.Getenv("WL_JIRA_USERNAME"),
+					},
+					&cli.StringFlag{
+						Name:  "jira-api-token",
+						Usage: "The API token for JIRA.",
+						Value: os.Getenv("WL_JIRA_API_TOKEN"),
+					},
+					&cli.StringFlag{
+						Name:  "jira-url",
+						Usage: "The URL for JIRA.",
+						Value: os.Getenv("WL_JIRA_URL"),
+					},
+					&cli.BoolFlag{
+						Name:  "details",
+						Usage: "Show the subject, project and status of the issues.",
+					},
+					&cli.BoolFlag{
+						Name:  "offline",
+						Usage: "Only use the local issue cache to look up issue details.",
+					},
+					&cli.StringFlag{
+						Name:  "group-by",
+						Value: "day",
+						Usage: "Group the entries with subtotals. Valid groups are 'day', 'issue' and 'project'.",
+					},
+					&cli.BoolFlag{
+						Name:  "running",
+						Usage: "Include the running interval up to now.",
+					},
 				},
 				Action: func(ctx *cli.Context) error {
+					if err := el.loadAliases(); err != nil {
+						return err
+					}
+
 					time_range := ctx.String("range")
 					if time_range != "all" && time_range != "month" && time_range != "week" && time_range != "day" {
 						log.Println("Invalid time range. Please use 'all', 'month', 'week', or 'day'.")
 						return nil
 					}
 
+					el.running = ctx.Bool("running")
+
 					if err := el.fromTimeWarrior(time_range); err != nil {
 						return err
 					}
 
+					config, err := loadConfig()
+					if err != nil {
+						return err
+					}
+					if err := el.applyRules(config.Rules); err != nil {
+						return err
+					}
+					wc, err := newWorkCalendar(config)
+					if err != nil {
+						return err
+					}
+					cc, err := config.Compliance.withDefaults()
+					if err != nil {
+						return err
+					}
+
+					// the comment templates may use the issue subjects
+					opts := listOptions{GroupBy: ctx.String("group-by")}
+					var issues map[string]IssueInfo
+					if ctx.Bool("details") || opts.GroupBy == "project" || config.Templates.Redmine.usesIssues() || config.Templates.Jira.usesIssues() {
+						resolver, err := newIssueResolver(ctx)
+						if err != nil {
+							return err
+						}
+						issues = resolver.ResolveAll(el.Entries)
+					}
+					if ctx.Bool("details") || opts.GroupBy == "project" {
+						opts.Issues = issues
+					}
+
+					el.validate(entryCheck, commentCheck(config.Templates, issues), distributionCheck, calendarCheck(wc), complianceCheck(cc), intervalCheck(config.Gaps.maxGap()))
+
 					if ctx.Bool("pending") {
 						el.filterPending()
 					}
 
-					table := el.list()
+					table, err := el.list(opts)
+					if err != nil {
+						return err
+					}
 					table.Render()
 
 					return nil
 				},
 			},
 			{
-				Name:  "untag",
-				Usage: "Remove a tag from a list of entries",
+				Name:  "report",
+				Usage: "Summarize the time entries per issue, project, activity, tag or sink.",
+				Flags: append(append([]cli.Flag{
+					&cli.StringFlag{
+						Name:  "range",
+						Value: "month",
+						Usage: "The time range to report. Valid ranges are 'all', 'month', 'week', and 'day'.",
+					},
+					&cli.StringFlag{
+						Name:  "by",
+						Value: "issue",
+						Usage: "The grouping of the report. Valid groups are 'issue', 'project', 'activity', 'tag' and 'sink'.",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "table",
+						Usage: "The output format. Valid formats are 'table', 'csv', 'json' and 'markdown'.",
+					},
+					&cli.BoolFlag{
+						Name:  "offline",
+						Usage: "Only use the local issue cache to look up projects.",
+					},
+					&cli.BoolFlag{
+						Name:  "running",
+						Usage: "Include the running interval up to now.",
+					},
+				}, redmineFlags()...), jiraFlags()...),
+				Action: func(ctx *cli.Context) error {
+					if err := el.loadAliases(); err != nil {
+						return err
+					}
+
+					time_range := ctx.String("range")
+					if time_range != "all" && time_range != "month" && time_range != "week" && time_range != "day" {
+						log.Println("Invalid time range. Please use 'all', 'month', 'week', or 'day'.")
+						return nil
+					}
+
+					el.running = ctx.Bool("running")
+
+					if err := el.fromTimeWarrior(time_range); err != nil {
+						return err
+					}
+
+					config, err := loadConfig()
+					if err != nil {
+						return err
+					}
+					if err := el.applyRules(config.Rules); err != nil {
+						return err
+					}
+
+					var issues map[string]IssueInfo
+					if ctx.String("by") == "project" {
+						resolver, err := newIssueResolver(ctx)
+						if err != nil {
+							return err
+						}
+						issues = resolver.ResolveAll(el.Entries)
+					}
+
+					report, err := el.report(ctx.String("by"), issues)
+					if err != nil {
+						return err
+					}
+
+					return report.write(os.Stdout, ctx.String("format"))
+				},
+			},
+			{
+				Name:  "timesheet",
+				Usage: "Show a weekly timesheet with the hours per issue and weekday.",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:  "tag",
-						Usage: "The tag to set to the entries",
+						Name:  "week",
+						Usage: "The ISO week of the timesheet, e.g. 2026-W41. Defaults to the current week.",
 					},
 					&cli.StringFlag{
-						Name:  "issueID",
-						Usage: "The issueIDs of the entries to tag",
+						Name:  "format",
+						Value: "table",
+						Usage: "The output format. Valid formats are 'table', 'csv' and 'html'.",
+					},
+					&cli.StringFlag{
+						Name:  "output",
+						Usage: "Write the timesheet to this file instead of stdout.",
+					},
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "The name printed on the HTML timesheet.",
+						Value: os.Getenv("WL_NAME"),
 					},
 				},
 				Action: func(ctx *cli.Context) error {
-					if err := el.fromTimeWarrior("all"); err != nil {
+					if err := el.loadAliases(); err != nil {
 						return err
 					}
 
-					tag := ctx.String("tag")
-					ID := ctx.String("issueID")
+					week := ctx.String("week")
+					if week == "" {
+						year, w := time.Now().In(localTime()).ISOWeek()
+						week = fmt.Sprintf("%d-W%02d", year, w)
+					}
 
-					for _, entry := range el.Entries {
-						if ID == "*" {
-							entry.unmark(tag)
-							continue
-						}
+					monday, err := parseISOWeek(week, localTime())
+					if err != nil {
+						return err
+					}
 
-						for _, issueID := range entry.IssueIDs {
-							if issueID == ID {
-								entry.unmark(tag)
-							}
+					if err := el.fromOwnRange("timesheet", monday, monday.AddDate(0, 0, 7)); err != nil {
+						return err
+					}
+
+					config, err := loadConfig()
+					if err != nil {
+						return err
+					}
+					if err := el.applyRules(config.Rules); err != nil {
+						return err
+					}
+
+					ts := el.timesheet(monday)
+					ts.Name = ctx.String("name")
+
+					out := os.Stdout
+					if ctx.String("output") != "" {
+						out, err = os.Create(ctx.String("output"))
+						if err != nil {
+							return err
 						}
+						defer out.Close()
 					}
 
-					return nil
+					return ts.write(out, ctx.String("format"))
 				},
 			},
+			{
+				Name:  "balance",
+				Usage: "Compare the tracked hours against the contractual hours and show the overtime balance.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "per",
+						Value: "week",
+						Usage: "The period of the rows. Valid periods are 'day', 'week' and 'month'.",
+					},
+					&cli.StringFlag{
+						Name:  "since",
+						Usage: "The date (YYYY-MM-DD) to start the balance from. Defaults to the start in the config.",
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					if err := el.loadAliases(); err != nil {
+						return err
+					}
+
+					config, err := loadConfig()
+					if err != nil {
+						return err
+					}
+
+					since := ctx.String("since")
+					if since == "" {
+						since = config.Balance.Start
+					}
+					if since == "" {
+						return fmt.Errorf("no start date, please use --since or set balance.start in the config")
+					}
 
+					loc := localTime()
+					from, err := time.ParseInLocation("2006-01-02", since, loc)
+					if err != nil {
+						return err
+					}
+					// today is left out until it is over, its full target would
+					// make the balance negative during the day
+					now := time.Now().In(loc)
+					to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+					wc, err := newWorkCalendar(config)
+					if err != nil {
+						return err
+					}
+
+					if err := el.fromOwnRange("balance", from, to); err != nil {
+						return err
+					}
+
+					rows, err := el.balance(wc, config.Balance.AbsenceTags, from, to, ctx.String("per"))
+					if err != nil {
+						return err
+					}
+
+					writeBalance(os.Stdout, rows)
+
+					return nil
+				},
+			},
 			{
-				Name:  "tag",
-				Usage: "Set a tag to a list of entries",
+				Name:  "compliance",
+				Usage: "Check the working times against breaks, daily maximum and rest periods.",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:  "tag",
-						Usage: "The tag to set to the entries",
+						Name:  "range",
+						Value: "month",
+						Usage: "The time range to check. Valid ranges are 'all', 'month', 'week', and 'day'.",
 					},
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "Show all days, not only those with violations.",
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					if err := el.loadAliases(); err != nil {
+						return err
+					}
+
+					time_range := ctx.String("range")
+					if time_range != "all" && time_range != "month" && time_range != "week" && time_range != "day" {
+						log.Println("Invalid time range. Please use 'all', 'month', 'week', or 'day'.")
+						return nil
+					}
+
+					config, err := loadConfig()
+					if err != nil {
+						return err
+					}
+					cc, err := config.Compliance.withDefaults()
+					if err != nil {
+						return err
+					}
+
+					if err := el.fromTimeWarrior(time_range); err != nil {
+						return err
+					}
+
+					if count := writeCompliance(os.Stdout, el.compliance(cc), ctx.Bool("all")); count > 0 {
+						return cli.NewExitError(fmt.Sprintf("%d violations of %s working time rules", count, cc.Jurisdiction), 1)
+					}
+
+					return nil
+				},
+			},
+			{
+				Name:  "gaps",
+				Usage: "Show overlapping intervals and gaps within the workday.",
+				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:  "issueID",
-						Usage: "The issueIDs of the entries to tag",
+						Name:  "range",
+						Value: "week",
+						Usage: "The time range to check. Valid ranges are 'all', 'month', 'week', and 'day'.",
+					},
+					&cli.Float64Flag{
+						Name:  "max-gap",
+						Usage: "The longest gap in minutes which is not reported. Defaults to the config or 30.",
+					},
+					&cli.BoolFlag{
+						Name:  "resolve",
+						Usage: "Resolve overlaps by trimming the start of the later interval.",
 					},
 				},
 				Action: func(ctx *cli.Context) error {
-					if err := el.fromTimeWarrior("all"); err != nil {
+					if err := el.loadAliases(); err != nil {
 						return err
 					}
 
-					for _, entry := range el.Entries {
-						for _, issueID := range entry.IssueIDs {
-							if issueID == ctx.String("issueID") {
-								entry.mark(ctx.String("tag"))
-							}
+					time_range := ctx.String("range")
+					if time_range != "all" && time_range != "month" && time_range != "week" && time_range != "day" {
+						log.Println("Invalid time range. Please use 'all', 'month', 'week', or 'day'.")
+						return nil
+					}
+
+					config, err := loadConfig()
+					if err != nil {
+						return err
+					}
+					if ctx.IsSet("max-gap") {
+						config.Gaps.MaxMinutes = ctx.Float64("max-gap")
+					}
+
+					// overlaps are resolved on the intervals as stored in timewarrior
+					el.raw = true
+					if err := el.fromTimeWarrior(time_range); err != nil {
+						return err
+					}
+
+					problems := el.intervalProblems(config.Gaps.maxGap())
+					writeIntervalProblems(os.Stdout, problems)
+
+					if !ctx.Bool("resolve") {
+						return nil
+					}
+
+					for _, problem := range problems {
+						if err := resolveOverlap(problem); err != nil {
+							log.Printf(">\t%s", err)
 						}
 					}
 
 					return nil
 				},
 			},
+			{
+				Name:  "split",
+				Usage: "Split the timewarrior intervals which cross midnight into one interval per day.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "range",
+						Value: "week",
+						Usage: "The time range to split. Valid ranges are 'all', 'month', 'week', and 'day'.",
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					if err := el.loadAliases(); err != nil {
+						return err
+					}
+
+					time_range := ctx.String("range")
+					if time_range != "all" && time_range != "month" && time_range != "week" && time_range != "day" {
+						log.Println("Invalid time range. Please use 'all', 'month', 'week', or 'day'.")
+						return nil
+					}
+
+					el.raw = true
+					if err := el.fromTimeWarrior(time_range); err != nil {
+						return err
+					}
+
+					count, err := el.splitIntervals(localTime())
+					if err != nil {
+						return err
+					}
+					log.Printf("Split %d intervals", count)
+
+					return nil
+				},
+			},
+			aliasCommand(),
+			startCommand(),
+			stopCommand(),
+			switchCommand(),
+			continueCommand(),
+			statusCommand(),
+			issuesCommand(),
+			triageCommand(),
+			commitsCommand(),
+			editCommand(),
+			untagCommand(),
+			tagCommand(),
+			retagCommand(),
+			historyCommand(),
+			restoreCommand(),
 			{
 				Name:  "log",
 				Usage: "Get the time entries from timewarrior and log them to other systems.",
@@ -171,8 +598,20 @@ func main() {
 								Value: "month",
 								Usage: "The time range to list. Valid ranges are 'month', 'week', and 'day'.",
 							},
+							&cli.BoolFlag{
+								Name:  "review",
+								Usage: "Review and edit the pending entries before they are pushed.",
+							},
+							&cli.BoolFlag{
+								Name:  "include-running",
+								Usage: "Also log the running interval up to now. It is stopped and continued as a new interval, which is logged next time.",
+							},
 						},
 						Action: func(ctx *cli.Context) error {
+							if err := el.loadAliases(); err != nil {
+								return err
+							}
+
 							rl := &RedmineLogger{
 								APIKey:       ctx.String("redmine-api-token"),
 								URL:          ctx.String("redmine-url"),
@@ -198,14 +637,54 @@ func main() {
 								fmt.Println("Invalid time range. Please use 'month', 'week', or 'day'.")
 							}
 
+							el.running = ctx.Bool("include-running")
 							if err := el.fromTimeWarrior(time_range); err != nil {
 								return err
 							}
 
-							projects := &Projects{}
-							redmineEntries := []TimeEntry{}
-							mapping := map[string]string{}
+							config, err := loadConfig()
+							if err != nil {
+								return err
+							}
+							if err := el.applyRules(config.Rules); err != nil {
+								return err
+							}
+
+							// entries with several Redmine issues are logged once per issue
+							entries := []TimeEntry{}
 							for _, entry := range el.Entries {
+								if !entry.IsRedmine {
+									continue
+								}
+
+								parts, err := entry.distribute(rl.TicketPrefix)
+								if err != nil {
+									log.Printf("Skipping %s: %s", entry.ID, err)
+									continue
+								}
+								entries = append(entries, parts...)
+							}
+							state := newSyncState("S2R", rl.TicketPrefix, entries)
+
+							if ctx.Bool("review") {
+								resolver, err := newIssueResolver(ctx)
+								if err != nil {
+									return err
+								}
+
+								entries, err = reviewEntries(state.pending(entries), rl.TicketPrefix, resolver, el.Aliases, config.Templates)
+								if err != nil {
+									return err
+								}
+								if entries == nil {
+									log.Println("Cancelled, nothing was logged.")
+									return nil
+								}
+							}
+
+							failed := 0
+							redmineEntries := []TimeEntry{}
+							for _, entry := range entries {
 								if entry.IsRedmine {
 									issueID, err := rl.getIssueID(entry.IssueIDs)
 									if err != nil {
@@ -215,7 +694,7 @@ func main() {
 									iID := strconv.FormatInt(issueID, 10)
 									log.Printf("Checking %s against Redmine.", iID)
 
-									issue, code, err := api.IssueSingleGet(issueID, redmine.IssueSingleGetRequest{})
+									_, code, err := api.IssueSingleGet(issueID, redmine.IssueSingleGetRequest{})
 									if code == 403 {
 										entry.errors = append(entry.errors, fmt.Sprintf("Access forbidden on %s: %d", iID, code))
 										log.Printf("Access forbidden on %s: %d", iID, code)
@@ -232,74 +711,28 @@ func main() {
 										continue
 									}
 
-									// handle activities
-									if entry.ActivityID == "" {
-										if val, ok := mapping[iID]; ok {
-											entry.ActivityID = val
-										} else {
-
-											log.Print("No activity ID found, asking the API for the activities...")
-											log.Printf("IssueID %s, Comment: %s", iID, entry.Comment)
-											pID := strconv.FormatInt(issue.Project.ID, 10)
-											rP, code, err := api.ProjectSingleGet(
-												pID,
-												redmine.ProjectSingleGetRequest{
-													Includes: []redmine.ProjectInclude{redmine.ProjectIncludeTimeEntryActivities},
-												},
-											)
-											if err != nil {
-												log.Printf("Error getting project %s: %s", issue.Project.Name, err)
-												continue
-											}
-											if code != 200 {
-												log.Printf("Error getting project %s: %d", issue.Project.Name, code)
-												continue
-											}
-
-											project := projects.GetProject(pID)
-											if project == nil {
-												project = &Project{
-													ID:         pID,
-													TimeEntrys: []TimeEntry{},
-													Activities: []Activity{},
-												}
-												project.TimeEntrys = append(project.TimeEntrys, entry)
-												projects.AddProject(*project)
-											}
-
-											if len(project.Activities) <= 0 {
-												for _, activity := range *rP.TimeEntryActivities {
-													project.Activities = append(project.Activities, Activity{
-														ID:  strconv.FormatInt(activity.ID, 10),
-														Tag: activity.Name,
-													})
-												}
-											}
-
-											log.Println("=====================================")
-											for index, activity := range project.Activities {
-												fmt.Printf("%d:\t%s\n", index, activity.Tag)
-											}
-											log.Println("=====================================")
-											var input string
-											fmt.Printf("Please enter the number of your activity: ")
-											fmt.Scanf("%s", &input)
-											selected, err := strconv.Atoi(input)
-											if err != nil {
-												return err
-											}
-
-											entry.ActivityID = project.Activities[selected].ID
-											mapping[iID] = entry.ActivityID
-										}
-
-										entry.mark(fmt.Sprintf("A_%s", entry.ActivityID))
+									// the activity is chosen in the review or tagged with A_
+									if entry.ActivityID == "" && !state.synced(entry) {
+										entry.errors = append(entry.errors, "Redmine entry without activity ID")
+										log.Printf("No activity for %s, please tag it with A_<id> or use --review", iID)
+										failed++
+										continue
 									}
 
 									redmineEntries = append(redmineEntries, entry)
 								}
 							}
 
+							var issues map[string]IssueInfo
+							if config.Templates.Redmine.usesIssues() {
+								resolver, err := newIssueResolver(ctx)
+								if err != nil {
+									return err
+								}
+								issues = resolver.ResolveAll(redmineEntries)
+							}
+							redmineEntries = config.Templates.sink(rl.TicketPrefix).applyTo(redmineEntries, rl.TicketPrefix, issues)
+
 							for _, entry := range redmineEntries {
 								issueID, err := rl.getIssueID(entry.IssueIDs)
 								if err != nil {
@@ -308,14 +741,7 @@ func main() {
 								iID := strconv.FormatInt(issueID, 10)
 								log.Printf("Logging %s", iID)
 
-								alreadySynced := false
-								for _, tag := range entry.Tags {
-									if tag == "S2R" {
-										alreadySynced = true
-										break
-									}
-								}
-								if alreadySynced {
+								if state.synced(entry) {
 									log.Println(">\tAlready synced to Redmine")
 									continue
 								}
@@ -325,10 +751,20 @@ func main() {
 									continue
 								}
 
-								err = rl.Log(entry)
-								if err != nil {
-									return err
+								if err := rl.Log(entry); err != nil {
+									log.Printf(">\t%s", err)
+									failed++
+									continue
 								}
+								state.done(entry)
+							}
+
+							// the pushed entries are marked even if others failed
+							if err := state.write(); err != nil {
+								return err
+							}
+							if failed > 0 {
+								return fmt.Errorf("could not log %d entries", failed)
 							}
 
 							return nil
@@ -358,8 +794,20 @@ func main() {
 								Value: "month",
 								Usage: "The time range to list. Valid ranges are 'month', 'week', and 'day'.",
 							},
+							&cli.BoolFlag{
+								Name:  "review",
+								Usage: "Review and edit the pending entries before they are pushed.",
+							},
+							&cli.BoolFlag{
+								Name:  "include-running",
+								Usage: "Also log the running interval up to now. It is stopped and continued as a new interval, which is logged next time.",
+							},
 						},
 						Action: func(ctx *cli.Context) error {
+							if err := el.loadAliases(); err != nil {
+								return err
+							}
+
 							jl := JiraLogger{
 								Username:     ctx.String("jira-username"),
 								Password:     ctx.String("jira-api-token"),
@@ -371,19 +819,61 @@ func main() {
 								log.Println("Invalid time range. Please use 'month', 'week', or 'day'.")
 							}
 
+							el.running = ctx.Bool("include-running")
 							if err := el.fromTimeWarrior(time_range); err != nil {
 								return err
 							}
 
+							config, err := loadConfig()
+							if err != nil {
+								return err
+							}
+							if err := el.applyRules(config.Rules); err != nil {
+								return err
+							}
+
 							jiraEntries := []TimeEntry{}
 							for _, entry := range el.Entries {
 								if entry.IsJira {
-									jiraEntries = append(jiraEntries, entry)
+									parts, err := entry.distribute(jl.TicketPrefix)
+									if err != nil {
+										log.Printf("Skipping %s: %s", entry.ID, err)
+										continue
+									}
+									jiraEntries = append(jiraEntries, parts...)
 								}
 							}
+							state := newSyncState("S2J", jl.TicketPrefix, jiraEntries)
+
+							if ctx.Bool("review") {
+								resolver, err := newIssueResolver(ctx)
+								if err != nil {
+									return err
+								}
+
+								jiraEntries, err = reviewEntries(state.pending(jiraEntries), jl.TicketPrefix, resolver, el.Aliases, config.Templates)
+								if err != nil {
+									return err
+								}
+								if jiraEntries == nil {
+									log.Println("Cancelled, nothing was logged.")
+									return nil
+								}
+							}
+
+							var issues map[string]IssueInfo
+							if config.Templates.Jira.usesIssues() {
+								resolver, err := newIssueResolver(ctx)
+								if err != nil {
+									return err
+								}
+								issues = resolver.ResolveAll(jiraEntries)
+							}
+							jiraEntries = config.Templates.sink(jl.TicketPrefix).applyTo(jiraEntries, jl.TicketPrefix, issues)
 
 							log.Printf("Found %d JIRA entries", len(jiraEntries))
 
+							failed := 0
 							for _, entry := range jiraEntries {
 								issueID, err := jl.getIssueID(entry.IssueIDs)
 								if err != nil {
@@ -391,19 +881,30 @@ func main() {
 								}
 								log.Printf("Logging time entry to JIRA: %s", issueID)
 
-								alreadySynced := false
-								for _, tag := range entry.Tags {
-									if tag == "S2J" {
-										alreadySynced = true
-										break
-									}
-								}
-								if alreadySynced {
+								if state.synced(entry) {
 									log.Println(">\tAlready synced to JIRA")
 									continue
 								}
 
-								jl.Log(entry)
+								if len(entry.errors) > 0 {
+									log.Printf(">\tSkipping due to errors: %s", strings.Join(entry.errors, ", "))
+									continue
+								}
+
+								if err := jl.Log(entry); err != nil {
+									log.Printf(">\t%s", err)
+									failed++
+									continue
+								}
+								state.done(entry)
+							}
+
+							// the pushed entries are marked even if others failed
+							if err := state.write(); err != nil {
+								return err
+							}
+							if failed > 0 {
+								return fmt.Errorf("could not log %d entries", failed)
 							}
 
 							return nil
@@ -414,7 +915,7 @@ func main() {
 		},
 	}
 
-	if err := app.Run(os.Args); err != nil {
+	if err := app.Run(args); err != nil {
 		log.Fatal(err)
 	}
 }
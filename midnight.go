@@ -0,0 +1,107 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"time"
+)
+
+// nextMidnight returns the start of the day following t.
+func nextMidnight(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+}
+
+// crossesMidnight reports whether the entry ends on a later day than it starts.
+func (te *TimeEntry) crossesMidnight(loc *time.Location) bool {
+	return te.End.After(nextMidnight(te.Start, loc))
+}
+
+// splitAtMidnight returns one portion of the entry per day. All portions keep
+// the ID of the entry; SyncState marks the interval once all are pushed.
+func (te TimeEntry) splitAtMidnight(loc *time.Location) []TimeEntry {
+	portions := []TimeEntry{}
+	start := te.Start
+	for {
+		portion := te
+		portion.Start = start
+
+		midnight := nextMidnight(start, loc)
+		if !te.End.After(midnight) {
+			portion.Hours = te.End.Sub(start)
+			return append(portions, portion)
+		}
+
+		portion.End = midnight
+		portion.Hours = midnight.Sub(start)
+		portions = append(portions, portion)
+		start = midnight
+	}
+}
+
+// splitAtMidnight replaces the entries crossing midnight by their portions,
+// so every entry is attributed to a single day.
+func (el *EntryList) splitAtMidnight(loc *time.Location) {
+	entries := []TimeEntry{}
+	for _, entry := range el.Entries {
+		entries = append(entries, entry.splitAtMidnight(loc)...)
+	}
+	el.Entries = entries
+}
+
+// splitInTimewarrior ends the interval at midnight and tracks the remaining
+// portions as new intervals with the same tags.
+func (te *TimeEntry) splitInTimewarrior(loc *time.Location) error {
+	portions := te.splitAtMidnight(loc)
+	if len(portions) < 2 {
+		return nil
+	}
+
+	if err := te.modifyEnd(portions[0].End); err != nil {
+		return err
+	}
+
+	for _, portion := range portions[1:] {
+		// the tags are passed as arguments, as the comment contains spaces
+		args := []string{
+			"track",
+			portion.Start.UTC().Format("20060102T150405Z"),
+			"-",
+			portion.End.UTC().Format("20060102T150405Z"),
+		}
+		args = append(args, te.timewTags...)
+
+		done := record(JournalChange{Action: "track", At: portion.Start, From: timeRef(portion.Start), To: timeRef(portion.End)})
+		if err := timew(args...); err != nil {
+			return err
+		}
+		done()
+	}
+
+	return nil
+}
+
+// splitIntervals splits the intervals crossing midnight in timewarrior. The
+// intervals are processed from the oldest to the newest, as tracking the new
+// portions shifts the IDs of all older intervals.
+func (el *EntryList) splitIntervals(loc *time.Location) (int, error) {
+	crossing := []TimeEntry{}
+	for _, entry := range el.Entries {
+		if entry.crossesMidnight(loc) {
+			crossing = append(crossing, entry)
+		}
+	}
+
+	sort.Slice(crossing, func(i, j int) bool {
+		return crossing[i].Start.Before(crossing[j].Start)
+	})
+
+	for i, entry := range crossing {
+		log.Printf("Splitting %s (%s - %s)", entry.ID, entry.Start.In(loc).Format("2006-01-02 15:04"), entry.End.In(loc).Format("2006-01-02 15:04"))
+		if err := entry.splitInTimewarrior(loc); err != nil {
+			return i, err
+		}
+	}
+
+	return len(crossing), nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+type GapConfig struct {
+	// MaxMinutes is the longest gap within a workday which is not reported.
+	MaxMinutes float64 `json:"maxMinutes"`
+}
+
+func (gc GapConfig) maxGap() time.Duration {
+	if gc.MaxMinutes <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(gc.MaxMinutes * float64(time.Minute))
+}
+
+// IntervalProblem is either an overlap of Entry with Previous or a gap
+// between both of them.
+type IntervalProblem struct {
+	Overlap  bool
+	Previous TimeEntry
+	Entry    TimeEntry
+	Duration time.Duration
+}
+
+func (ip IntervalProblem) String() string {
+	if ip.Overlap {
+		return fmt.Sprintf("Overlaps %s by %s", ip.Previous.ID, formatDuration(ip.Duration))
+	}
+	return fmt.Sprintf("Gap of %s after %s", formatDuration(ip.Duration), ip.Previous.ID)
+}
+
+// intervalProblems finds overlapping intervals and gaps longer than maxGap
+// between intervals on the same day.
+func (el *EntryList) intervalProblems(maxGap time.Duration) []IntervalProblem {
+	entries := append([]TimeEntry{}, el.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+
+	loc := localTime()
+	problems := []IntervalProblem{}
+	if len(entries) == 0 {
+		return problems
+	}
+
+	// compare against the interval which ends last so far, a long interval
+	// can overlap several later ones
+	previous := entries[0]
+	for _, entry := range entries[1:] {
+		if entry.Start.Before(previous.End) {
+			end := previous.End
+			if entry.End.Before(end) {
+				end = entry.End
+			}
+			problems = append(problems, IntervalProblem{
+				Overlap:  true,
+				Previous: previous,
+				Entry:    entry,
+				Duration: end.Sub(entry.Start),
+			})
+		} else {
+			sameDay := previous.End.In(loc).Format("2006-01-02") == entry.Start.In(loc).Format("2006-01-02")
+			if gap := entry.Start.Sub(previous.End); sameDay && gap > maxGap {
+				problems = append(problems, IntervalProblem{
+					Previous: previous,
+					Entry:    entry,
+					Duration: gap,
+				})
+			}
+		}
+
+		if entry.End.After(previous.End) {
+			previous = entry
+		}
+	}
+
+	return problems
+}
+
+// intervalCheck shows overlaps and gaps as problems in `list`.
+func intervalCheck(maxGap time.Duration) Check {
+	return func(entries []TimeEntry) map[string][]string {
+		el := EntryList{Entries: entries}
+		problems := map[string][]string{}
+		for _, problem := range el.intervalProblems(maxGap) {
+			problems[problem.Entry.key()] = append(problems[problem.Entry.key()], problem.String())
+		}
+		return problems
+	}
+}
+
+// resolveOverlap trims the start of the later interval to the end of the
+// earlier one. Intervals which are contained completely can not be trimmed.
+func resolveOverlap(problem IntervalProblem) error {
+	if !problem.Overlap {
+		return nil
+	}
+	if !problem.Entry.End.After(problem.Previous.End) {
+		return fmt.Errorf("interval %s lies within %s and can not be trimmed", problem.Entry.ID, problem.Previous.ID)
+	}
+
+	log.Printf("Trimming start of %s to %s", problem.Entry.ID, problem.Previous.End.In(localTime()).Format("2006-01-02 15:04:05"))
+	return problem.Entry.modifyStart(problem.Previous.End)
+}
+
+func writeIntervalProblems(w io.Writer, problems []IntervalProblem) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Kind", "Previous", "Ends", "Entry", "Starts", "Duration"})
+
+	loc := localTime()
+	for _, problem := range problems {
+		kind := "gap"
+		if problem.Overlap {
+			kind = "overlap"
+		}
+		table.Append([]string{
+			kind,
+			problem.Previous.ID,
+			problem.Previous.End.In(loc).Format("2006-01-02 15:04"),
+			problem.Entry.ID,
+			problem.Entry.Start.In(loc).Format("2006-01-02 15:04"),
+			formatDuration(problem.Duration),
+		})
+	}
+
+	table.Render()
+}
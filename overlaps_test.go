@@ -0,0 +1,118 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntervalProblems(t *testing.T) {
+	tests := []struct {
+		name      string
+		intervals [][3]string
+		maxGap    time.Duration
+		expected  []string
+	}{
+		{
+			name: "chained overlaps",
+			intervals: [][3]string{
+				{"3", "2026-10-05 08:00", "2026-10-05 10:00"},
+				{"2", "2026-10-05 09:30", "2026-10-05 11:00"},
+				{"1", "2026-10-05 10:45", "2026-10-05 12:00"},
+			},
+			maxGap:   30 * time.Minute,
+			expected: []string{"2: Overlaps 3 by 0:30", "1: Overlaps 2 by 0:15"},
+		},
+		{
+			name: "nested overlaps",
+			intervals: [][3]string{
+				{"3", "2026-10-05 08:00", "2026-10-05 12:00"},
+				{"2", "2026-10-05 09:00", "2026-10-05 10:00"},
+				{"1", "2026-10-05 11:00", "2026-10-05 13:00"},
+			},
+			maxGap:   30 * time.Minute,
+			expected: []string{"2: Overlaps 3 by 1:00", "1: Overlaps 3 by 1:00"},
+		},
+		{
+			name: "gap on the same day",
+			intervals: [][3]string{
+				{"2", "2026-10-05 08:00", "2026-10-05 10:00"},
+				{"1", "2026-10-05 11:00", "2026-10-05 12:00"},
+			},
+			maxGap:   30 * time.Minute,
+			expected: []string{"1: Gap of 1:00 after 2"},
+		},
+		{
+			name: "gap within the threshold",
+			intervals: [][3]string{
+				{"2", "2026-10-05 08:00", "2026-10-05 10:00"},
+				{"1", "2026-10-05 11:00", "2026-10-05 12:00"},
+			},
+			maxGap:   GapConfig{MaxMinutes: 60}.maxGap(),
+			expected: []string{},
+		},
+		{
+			name: "gap across midnight",
+			intervals: [][3]string{
+				{"2", "2026-10-05 20:00", "2026-10-05 22:00"},
+				{"1", "2026-10-06 08:00", "2026-10-06 09:00"},
+			},
+			maxGap:   30 * time.Minute,
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		el := EntryList{}
+		for _, interval := range test.intervals {
+			el.Entries = append(el.Entries, complianceEntry(interval[0], interval[1], interval[2]))
+		}
+
+		problems := el.intervalProblems(test.maxGap)
+		if len(problems) != len(test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, problems)
+			continue
+		}
+		for i, problem := range problems {
+			if got := problem.Entry.ID + ": " + problem.String(); got != test.expected[i] {
+				t.Errorf("%s: expected %q, got %q", test.name, test.expected[i], got)
+			}
+		}
+	}
+}
+
+func TestGapConfigMaxGap(t *testing.T) {
+	if gap := (GapConfig{}).maxGap(); gap != 30*time.Minute {
+		t.Errorf("Expected 30 minutes by default, got %s", gap)
+	}
+	if gap := (GapConfig{MaxMinutes: 45}).maxGap(); gap != 45*time.Minute {
+		t.Errorf("Expected 45 minutes, got %s", gap)
+	}
+}
+
+func TestIntervalCheck(t *testing.T) {
+	entries := []TimeEntry{
+		complianceEntry("2", "2026-10-05 08:00", "2026-10-05 10:00"),
+		complianceEntry("1", "2026-10-05 09:00", "2026-10-05 11:00"),
+	}
+
+	problems := intervalCheck(30 * time.Minute)(entries)
+	if len(problems[entries[0].key()]) != 0 {
+		t.Errorf("Expected no problem for the earlier interval, got %v", problems[entries[0].key()])
+	}
+	if p := problems[entries[1].key()]; len(p) != 1 || p[0] != "Overlaps 2 by 1:00" {
+		t.Errorf("Expected the overlap on the later interval, got %v", p)
+	}
+}
+
+func TestResolveOverlap(t *testing.T) {
+	outer := complianceEntry("2", "2026-10-05 08:00", "2026-10-05 12:00")
+	inner := complianceEntry("1", "2026-10-05 09:00", "2026-10-05 10:00")
+
+	if err := resolveOverlap(IntervalProblem{Overlap: true, Previous: outer, Entry: inner, Duration: time.Hour}); err == nil {
+		t.Errorf("Expected an error for a contained interval")
+	}
+	// gaps are left alone
+	if err := resolveOverlap(IntervalProblem{Previous: inner, Entry: outer}); err != nil {
+		t.Errorf("Expected no error for a gap, got %s", err)
+	}
+}
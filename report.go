@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+type ReportRow struct {
+	Group   string  `json:"group"`
+	Entries int     `json:"entries"`
+	Hours   float64 `json:"hours"`
+	Share   float64 `json:"share"`
+	Synced  float64 `json:"synced"`
+	Pending float64 `json:"pending"`
+}
+
+type Report struct {
+	By    string      `json:"by"`
+	Rows  []ReportRow `json:"rows"`
+	Total float64     `json:"total"`
+	// Running holds the hours of the running interval included in the total.
+	Running float64 `json:"running,omitempty"`
+}
+
+// reportKeys returns the groups an entry is counted in. Grouping by tag or
+// sink can put an entry into several groups, so the shares of those reports
+// may add up to more than 100%.
+func reportKeys(by string, issues map[string]IssueInfo) (func(TimeEntry) []string, error) {
+	switch by {
+	case "issue", "project":
+		key, err := groupKey(by, issues)
+		if err != nil {
+			return nil, err
+		}
+		return func(te TimeEntry) []string {
+			return []string{key(te)}
+		}, nil
+	case "activity":
+		return func(te TimeEntry) []string {
+			if te.ActivityID == "" {
+				return []string{"-"}
+			}
+			return []string{te.ActivityID}
+		}, nil
+	case "tag":
+		return func(te TimeEntry) []string {
+			// the sync markers and activities are no tags of the work
+			tags := triageTags(te)
+			if len(tags) == 0 {
+				return []string{"-"}
+			}
+			return tags
+		}, nil
+	case "sink":
+		return func(te TimeEntry) []string {
+			sinks := []string{}
+			if te.IsRedmine {
+				sinks = append(sinks, "redmine")
+			}
+			if te.IsJira {
+				sinks = append(sinks, "jira")
+			}
+			if len(sinks) == 0 {
+				return []string{"-"}
+			}
+			return sinks
+		}, nil
+	}
+
+	return nil, fmt.Errorf("invalid grouping %q, please use 'issue', 'project', 'activity', 'tag' or 'sink'", by)
+}
+
+func (el *EntryList) report(by string, issues map[string]IssueInfo) (*Report, error) {
+	keys, err := reportKeys(by, issues)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{By: by}
+	rows := map[string]*ReportRow{}
+	for _, entry := range el.Entries {
+		hours := entry.Hours.Hours()
+		report.Total += hours
+		if entry.Running {
+			report.Running += hours
+		}
+
+		for _, k := range keys(entry) {
+			row, ok := rows[k]
+			if !ok {
+				row = &ReportRow{Group: k}
+				rows[k] = row
+			}
+
+			row.Entries++
+			row.Hours += hours
+
+			synced := entry.isSynced()
+			if by == "sink" {
+				// per sink only the marker of that sink is relevant
+				synced = (k == "redmine" && entry.hasTag("S2R")) || (k == "jira" && entry.hasTag("S2J"))
+			}
+			if synced {
+				row.Synced += hours
+			} else {
+				row.Pending += hours
+			}
+		}
+	}
+
+	for _, row := range rows {
+		if report.Total > 0 {
+			row.Share = row.Hours / report.Total * 100
+		}
+		report.Rows = append(report.Rows, *row)
+	}
+
+	sort.SliceStable(report.Rows, func(i, j int) bool {
+		if report.Rows[i].Hours != report.Rows[j].Hours {
+			return report.Rows[i].Hours > report.Rows[j].Hours
+		}
+		return report.Rows[i].Group < report.Rows[j].Group
+	})
+
+	return report, nil
+}
+
+func (r *Report) header() []string {
+	return []string{strings.ToUpper(r.By[:1]) + r.By[1:], "Entries", "Hours", "Share", "Synced", "Pending"}
+}
+
+func (r *Report) records() [][]string {
+	records := [][]string{}
+	for _, row := range r.Rows {
+		records = append(records, []string{
+			row.Group,
+			fmt.Sprintf("%d", row.Entries),
+			fmt.Sprintf("%.2f", row.Hours),
+			fmt.Sprintf("%.1f%%", row.Share),
+			fmt.Sprintf("%.2f", row.Synced),
+			fmt.Sprintf("%.2f", row.Pending),
+		})
+	}
+	return records
+}
+
+func (r *Report) write(w io.Writer, format string) error {
+	switch format {
+	case "table", "markdown":
+		table := tablewriter.NewWriter(w)
+		table.SetHeader(r.header())
+		table.AppendBulk(r.records())
+		if format == "markdown" {
+			table.SetAutoFormatHeaders(false)
+			table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+			table.SetCenterSeparator("|")
+			table.Append([]string{"**Total**", " ", fmt.Sprintf("**%.2f**", r.Total), " ", " ", " "})
+		} else {
+			table.SetFooter([]string{"Total", " ", fmt.Sprintf("%.2f", r.Total), " ", " ", " "})
+		}
+		table.Render()
+		if r.Running > 0 {
+			fmt.Fprintf(w, "The total includes %.2f hours of the running interval.\n", r.Running)
+		}
+		return nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(r.header()); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(r.records()); err != nil {
+			return err
+		}
+		return cw.Error()
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+
+	return fmt.Errorf("invalid format %q, please use 'table', 'csv', 'json' or 'markdown'", format)
+}
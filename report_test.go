@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func reportEntries() EntryList {
+	return EntryList{Entries: []TimeEntry{
+		{ID: "4", IssueIDs: []string{"#100"}, Hours: 3 * time.Hour, IsRedmine: true, Tags: []string{"S2R", "A_9", "infra"}},
+		{ID: "3", IssueIDs: []string{"#100"}, Hours: time.Hour, IsRedmine: true, Tags: []string{"S2R:#100@2026-10-05", "infra"}},
+		{ID: "2", IssueIDs: []string{"#200", "PIM-5"}, Hours: 2 * time.Hour, IsRedmine: true, IsJira: true, Tags: []string{"S2J", "meeting"}},
+		{ID: "1", Hours: 2 * time.Hour},
+	}}
+}
+
+func reportRows(t *testing.T, by string, issues map[string]IssueInfo) (*Report, map[string]ReportRow) {
+	t.Helper()
+	el := reportEntries()
+	report, err := el.report(by, issues)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	rows := map[string]ReportRow{}
+	for _, row := range report.Rows {
+		rows[row.Group] = row
+	}
+	return report, rows
+}
+
+func TestReportByIssue(t *testing.T) {
+	report, rows := reportRows(t, "issue", nil)
+	if report.Total != 8 {
+		t.Errorf("Expected 8 hours in total, got %.2f", report.Total)
+	}
+	if len(report.Rows) != 3 || report.Rows[0].Group != "#100" {
+		t.Fatalf("Expected 3 groups starting with #100, got %+v", report.Rows)
+	}
+
+	// the partially synced entry is pending
+	row := rows["#100"]
+	if row.Entries != 2 || row.Hours != 4 || row.Share != 50 || row.Synced != 3 || row.Pending != 1 {
+		t.Errorf("Unexpected #100 row %+v", row)
+	}
+	// synced to JIRA only, so still pending for Redmine
+	if row := rows["#200, PIM-5"]; row.Synced != 0 || row.Pending != 2 || row.Share != 25 {
+		t.Errorf("Unexpected #200 row %+v", row)
+	}
+	if row := rows["-"]; row.Pending != 2 {
+		t.Errorf("Unexpected row without issue %+v", row)
+	}
+}
+
+func TestReportBySink(t *testing.T) {
+	_, rows := reportRows(t, "sink", nil)
+	if row := rows["redmine"]; row.Entries != 3 || row.Hours != 6 || row.Synced != 3 || row.Pending != 3 {
+		t.Errorf("Unexpected redmine row %+v", row)
+	}
+	if row := rows["jira"]; row.Entries != 1 || row.Synced != 2 || row.Pending != 0 {
+		t.Errorf("Unexpected jira row %+v", row)
+	}
+	if row := rows["-"]; row.Hours != 2 {
+		t.Errorf("Unexpected row without sink %+v", row)
+	}
+}
+
+func TestReportByProject(t *testing.T) {
+	issues := map[string]IssueInfo{
+		"#100": {ID: "#100", Project: "Infrastructure"},
+		"#200": {ID: "#200", Project: "Internal"},
+	}
+	_, rows := reportRows(t, "project", issues)
+	if row := rows["Infrastructure"]; row.Hours != 4 {
+		t.Errorf("Unexpected Infrastructure row %+v", row)
+	}
+	if row := rows["Internal"]; row.Hours != 2 {
+		t.Errorf("Unexpected Internal row %+v", row)
+	}
+	if row := rows["-"]; row.Hours != 2 {
+		t.Errorf("Unexpected row without project %+v", row)
+	}
+}
+
+func TestReportByTag(t *testing.T) {
+	report, rows := reportRows(t, "tag", nil)
+	for _, row := range report.Rows {
+		switch row.Group {
+		case "S2R", "S2J", "S2R:#100@2026-10-05", "A_9":
+			t.Errorf("Expected no group for the marker %s", row.Group)
+		}
+	}
+	if row := rows["infra"]; row.Entries != 2 || row.Hours != 4 {
+		t.Errorf("Unexpected infra row %+v", row)
+	}
+	if row := rows["-"]; row.Hours != 2 {
+		t.Errorf("Unexpected row without tags %+v", row)
+	}
+
+	if _, err := (&EntryList{}).report("month", nil); err == nil {
+		t.Errorf("Expected an error for an invalid grouping")
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+// renameSource returns the tag of the interval which a rename of from would
+// replace. Issues are found whatever their notation.
+func (te *TimeEntry) renameSource(from string, aliases map[string]Alias) string {
+	if issueID, err := normalizeIssue(from); err == nil {
+		return te.issueTagOf(issueID, aliases)
+	}
+
+	for _, tag := range te.timewTags {
+		if tag == from {
+			return tag
+		}
+	}
+	return ""
+}
+
+// renameTags returns the tag of the interval which a rename of from to to
+// replaces, and the tag replacing it. Issues keep their weight.
+func (te *TimeEntry) renameTags(from, to string, aliases map[string]Alias) (string, string) {
+	source := te.renameSource(from, aliases)
+	if source == "" {
+		return "", ""
+	}
+
+	_, fromErr := normalizeIssue(from)
+	toIssue, toErr := normalizeIssue(to)
+	if fromErr != nil || toErr != nil {
+		return source, to
+	}
+
+	target := issueTag(toIssue)
+	if i := strings.Index(source, ":"); i > 0 {
+		target += source[i:]
+	}
+	return source, target
+}
+
+// movedJira finds the JIRA issues of the entries which were moved. JIRA
+// redirects the old key, so the issue is returned with its new key.
+func (r *IssueResolver) movedJira(entries []TimeEntry) (map[string]string, error) {
+	if r.Jira == nil {
+		return nil, fmt.Errorf("JIRA is not configured")
+	}
+	if r.jiraClient == nil {
+		client, err := r.Jira.getJiraClient()
+		if err != nil {
+			return nil, err
+		}
+		r.jiraClient = client
+	}
+
+	moved := map[string]string{}
+	checked := map[string]bool{}
+	for _, entry := range entries {
+		for _, issueID := range entry.issuesWithPrefix(r.Jira.TicketPrefix) {
+			if checked[issueID] {
+				continue
+			}
+			checked[issueID] = true
+
+			issue, err := r.Jira.getIssue(r.jiraClient, issueID)
+			if err != nil {
+				log.Printf("Could not check %s: %s", issueID, err)
+				continue
+			}
+			if issue.Key != issueID {
+				moved[issueID] = issue.Key
+			}
+		}
+	}
+
+	return moved, nil
+}
+
+func retagCommand() cli.Command {
+	return cli.Command{
+		Name:  "retag",
+		Usage: "Replace a tag or issue on all intervals of a time range.",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "The tag or issue to replace, e.g. 'R_123' or 'PIM-45'.",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "The new tag or issue.",
+			},
+			&cli.StringFlag{
+				Name:  "range",
+				Value: "all",
+				Usage: "The time range to retag. Valid ranges are 'all', 'month', 'week', and 'day'.",
+			},
+			&cli.BoolFlag{
+				Name:  "check-moved",
+				Usage: "Find JIRA issues which were moved and offer to migrate their tags.",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only print the intervals which would change.",
+			},
+			&cli.BoolFlag{
+				Name:  "offline",
+				Usage: "Do not validate the new issue.",
+			},
+		}, append(redmineFlags(), jiraFlags()...)...),
+		Action: func(ctx *cli.Context) error {
+			aliases, err := aliasMap()
+			if err != nil {
+				return err
+			}
+
+			time_range := ctx.String("range")
+			if time_range != "all" && time_range != "month" && time_range != "week" && time_range != "day" {
+				return fmt.Errorf("invalid time range, please use 'all', 'month', 'week', or 'day'")
+			}
+
+			el := EntryList{Aliases: aliases, raw: true}
+			if err := el.fromTimeWarrior(time_range); err != nil {
+				return err
+			}
+
+			resolver, err := newIssueResolver(ctx)
+			if err != nil {
+				return err
+			}
+
+			renames := [][2]string{}
+			if ctx.Bool("check-moved") {
+				moved, err := resolver.movedJira(el.Entries)
+				if err != nil {
+					return err
+				}
+				renames = append(renames, movedRenames(moved)...)
+			} else {
+				from, to := ctx.String("from"), ctx.String("to")
+				if from == "" || to == "" {
+					return fmt.Errorf("please give --from and --to, or use --check-moved")
+				}
+				if from == to {
+					return fmt.Errorf("--from and --to are the same")
+				}
+
+				if toIssue, err := normalizeIssue(to); err == nil {
+					if _, err := normalizeIssue(from); err != nil {
+						return fmt.Errorf("%s is an issue, so %s has to be an issue as well", to, from)
+					}
+					if !resolver.Offline {
+						info, err := resolver.Resolve(toIssue)
+						if err != nil {
+							return err
+						}
+						log.Printf("%s: %s (%s)", toIssue, info.Subject, info.Project)
+					}
+				}
+				renames = append(renames, [2]string{from, to})
+			}
+
+			for _, rename := range renames {
+				if err := retagEntries(el.Entries, rename[0], rename[1], aliases, ctx.Bool("dry-run")); err != nil {
+					return err
+				}
+			}
+
+			return resolver.Save()
+		},
+	}
+}
+
+// movedRenames prints the moved issues and returns them for migration after
+// confirmation. Issues moved to other projects are tagged with their key,
+// e.g. `J_OPS-45`.
+func movedRenames(moved map[string]string) [][2]string {
+	if len(moved) == 0 {
+		fmt.Println("No moved JIRA issues found.")
+		return nil
+	}
+
+	oldIDs := []string{}
+	for oldID := range moved {
+		oldIDs = append(oldIDs, oldID)
+	}
+	sort.Strings(oldIDs)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Old", "New", "Migration"})
+	renames := [][2]string{}
+	for _, oldID := range oldIDs {
+		newID := moved[oldID]
+		table.Append([]string{oldID, newID, issueTag(oldID) + " -> " + issueTag(newID)})
+		renames = append(renames, [2]string{oldID, newID})
+	}
+	table.Render()
+
+	fmt.Print("Migrate the tags? [y/N]: ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+		return nil
+	}
+	return renames
+}
+
+// retagEntries renames the tag on all entries with one batch per tag.
+// Weighted issues are batched per weight, which their new tag keeps.
+func retagEntries(entries []TimeEntry, from, to string, aliases map[string]Alias, dryRun bool) error {
+	matched := []TimeEntry{}
+	untag, tag := map[string][]TimeEntry{}, map[string][]TimeEntry{}
+	for i, entry := range entries {
+		source, target := entry.renameTags(from, to, aliases)
+		if source == "" {
+			continue
+		}
+		matched = append(matched, entry)
+		untag[source] = append(untag[source], entry)
+		tag[target] = append(tag[target], entry)
+
+		// later renames see the new tags
+		if !dryRun {
+			entries[i].timewTags = replaceTags(entry.timewTags, []string{source}, []string{target})
+		}
+	}
+
+	if !dryRun {
+		if err := changeTagGroups("untag", untag); err != nil {
+			return err
+		}
+		if err := changeTagGroups("tag", tag); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("%s -> %s: %d intervals\n", from, to, len(matched))
+	writeSelection(matched)
+	return nil
+}
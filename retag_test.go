@@ -0,0 +1,101 @@
+package main
+
+import "testing"
+
+func TestTimeEntryRenameSource(t *testing.T) {
+	aliases := map[string]Alias{"infra": {Issue: "#48"}}
+	entry, err := parse(TimeWarriorEntry{
+		ID:    1,
+		Start: "20261005T080000Z",
+		End:   "20261005T100000Z",
+		Tags:  []string{"R_100:70", "infra", "J_5", "meeting", "fixed the login"},
+	}, aliases)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	tests := map[string]string{
+		"R_100":   "R_100:70",
+		"#100":    "R_100:70",
+		"#48":     "infra",
+		"PIM-5":   "J_5",
+		"J_5":     "J_5",
+		"meeting": "meeting",
+		"R_10":    "",
+		"standup": "",
+	}
+	for from, expected := range tests {
+		if source := entry.renameSource(from, aliases); source != expected {
+			t.Errorf("Expected %q for %s, got %q", expected, from, source)
+		}
+	}
+}
+
+func TestMovedJiraIssue(t *testing.T) {
+	// PIM-123 was moved to OPS-45
+	if tag := issueTag("OPS-45"); tag != "J_OPS-45" {
+		t.Errorf("Expected J_OPS-45, got %s", tag)
+	}
+	for _, issue := range []string{"J_OPS-45", "OPS-45"} {
+		if issueID, err := normalizeIssue(issue); err != nil || issueID != "OPS-45" {
+			t.Errorf("Expected OPS-45 for %s, got %q (%v)", issue, issueID, err)
+		}
+	}
+
+	entry, err := parse(TimeWarriorEntry{
+		ID:    1,
+		Start: "20261005T080000Z",
+		End:   "20261005T100000Z",
+		Tags:  []string{"J_OPS-45:2", "J_123:1", "fixed the login"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !entry.IsJira || len(entry.IssueIDs) != 2 || entry.IssueIDs[0] != "OPS-45" || entry.Weights["OPS-45"] != 2 {
+		t.Errorf("Expected the JIRA issues OPS-45 and PIM-123, got %v with %v", entry.IssueIDs, entry.Weights)
+	}
+	if issueIDs := entry.issuesWithPrefix("PIM-"); len(issueIDs) != 2 {
+		t.Errorf("Expected both issues for JIRA, got %v", issueIDs)
+	}
+	if source := entry.renameSource("PIM-123", nil); source != "J_123:1" {
+		t.Errorf("Expected J_123:1 for PIM-123, got %q", source)
+	}
+	if source := entry.renameSource("OPS-45", nil); source != "J_OPS-45:2" {
+		t.Errorf("Expected J_OPS-45:2 for OPS-45, got %q", source)
+	}
+
+	jl := JiraLogger{TicketPrefix: "PIM-"}
+	if issueID, _ := jl.getIssueID([]string{"#7", "OPS-45"}); issueID != "OPS-45" {
+		t.Errorf("Expected OPS-45 to be logged to JIRA, got %q", issueID)
+	}
+}
+
+func TestTimeEntryRenameTags(t *testing.T) {
+	aliases := map[string]Alias{"infra": {Issue: "#48"}}
+	entry, err := parse(TimeWarriorEntry{
+		ID:    1,
+		Start: "20261005T080000Z",
+		End:   "20261005T100000Z",
+		Tags:  []string{"R_100:70", "infra", "J_123", "meeting", "fixed the login"},
+	}, aliases)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	tests := []struct {
+		from, to       string
+		source, target string
+	}{
+		{"#100", "#101", "R_100:70", "R_101:70"},
+		{"#48", "R_49", "infra", "R_49"},
+		{"PIM-123", "OPS-45", "J_123", "J_OPS-45"},
+		{"meeting", "standup", "meeting", "standup"},
+		{"#7", "#8", "", ""},
+	}
+	for _, test := range tests {
+		source, target := entry.renameTags(test.from, test.to, aliases)
+		if source != test.source || target != test.target {
+			t.Errorf("%s -> %s: expected %q -> %q, got %q -> %q", test.from, test.to, test.source, test.target, source, target)
+		}
+	}
+}
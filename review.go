@@ -0,0 +1,494 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+const reviewHelp = `up/down or j/k select   c comment   a activity   i issue   x exclude/include
+y write the edits and push   q quit without pushing`
+
+// keys of the review screen which are not printable characters
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyEnter     = "enter"
+	keyBackspace = "backspace"
+	keyCancel    = "cancel"
+)
+
+// Review is a full-screen terminal UI to check and edit entries before they
+// are pushed to a tracker. Edits are kept in memory until the push is
+// confirmed.
+type Review struct {
+	Entries  []TimeEntry
+	Prefix   string
+	Resolver *IssueResolver
+	Aliases  map[string]Alias
+	// Templates render the comments as they are pushed.
+	Templates TemplateConfig
+
+	original   []TimeEntry
+	excluded   []bool
+	issues     map[string]IssueInfo
+	activities map[string][]Activity
+	cursor     int
+	message    string
+}
+
+func newReview(entries []TimeEntry, prefix string, resolver *IssueResolver, aliases map[string]Alias) *Review {
+	review := &Review{
+		Prefix:     prefix,
+		Resolver:   resolver,
+		Aliases:    aliases,
+		original:   entries,
+		excluded:   make([]bool, len(entries)),
+		issues:     map[string]IssueInfo{},
+		activities: map[string][]Activity{},
+	}
+
+	for _, entry := range entries {
+		entry.IssueIDs = append([]string{}, entry.IssueIDs...)
+		review.Entries = append(review.Entries, entry)
+	}
+
+	return review
+}
+
+// issueOf returns the issue of the entry in the reviewed tracker.
+func (r *Review) issueOf(entry TimeEntry) string {
+	issueIDs := entry.issuesWithPrefix(r.Prefix)
+	if len(issueIDs) == 0 {
+		return ""
+	}
+	return issueIDs[0]
+}
+
+// projectActivities returns the Redmine activities of the project, which are
+// only known online.
+func (r *Review) projectActivities(info IssueInfo) []Activity {
+	if r.Prefix != "#" || r.Resolver.Redmine == nil || r.Resolver.Offline || info.ProjectID == "" {
+		return nil
+	}
+
+	activities, ok := r.activities[info.ProjectID]
+	if !ok {
+		activities, _ = r.Resolver.Redmine.getActivities(info.ProjectID)
+		r.activities[info.ProjectID] = activities
+	}
+	return activities
+}
+
+// activityName looks up the name of a Redmine activity in the project.
+func (r *Review) activityName(info IssueInfo, activityID string) string {
+	if activityID == "" {
+		return activityID
+	}
+
+	for _, activity := range r.projectActivities(info) {
+		if activity.ID == activityID {
+			return fmt.Sprintf("%s (%s)", activity.Tag, activityID)
+		}
+	}
+	return activityID
+}
+
+func (r *Review) render(w io.Writer) {
+	// clear the screen and move the cursor to the top
+	fmt.Fprint(w, "\033[H\033[2J")
+
+	// the issues are looked up once, not on every key
+	for _, entry := range r.Entries {
+		issueID := r.issueOf(entry)
+		if _, ok := r.issues[issueID]; !ok && issueID != "" {
+			r.issues[issueID], _ = r.Resolver.Resolve(issueID)
+		}
+	}
+	issues := r.issues
+
+	el := EntryList{Entries: append([]TimeEntry{}, r.Entries...)}
+	el.validate(entryCheck, commentCheck(r.Templates, issues), distributionCheck)
+	st := r.Templates.sink(r.Prefix)
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"#", "ID", "Date", "Hours", "Issue", "Subject", "Activity", "Comment", "Problems"})
+	table.SetAutoWrapText(false)
+
+	total := 0.0
+	loc := localTime()
+	for index, entry := range el.Entries {
+		issueID := r.issueOf(entry)
+		info := issues[issueID]
+		comment, _, err := st.render(entry, r.Prefix, issues)
+		if err != nil {
+			comment = entry.Comment
+		}
+
+		marker := strconv.Itoa(index)
+		if r.excluded[index] {
+			marker = "x " + marker
+		} else {
+			total += entry.Hours.Hours()
+		}
+		if index == r.cursor {
+			marker = "> " + marker
+		}
+
+		table.Append([]string{
+			marker,
+			entry.ID,
+			entry.Start.In(loc).Format("2006-01-02"),
+			fmt.Sprintf("%.2f", entry.Hours.Hours()),
+			issueID,
+			info.Subject,
+			r.activityName(info, entry.ActivityID),
+			comment,
+			strings.Join(entry.errors, "\n"),
+		})
+	}
+	table.SetFooter([]string{" ", " ", "Total", fmt.Sprintf("%.2f", total), " ", " ", " ", " ", " "})
+	table.Render()
+
+	fmt.Fprintln(w, reviewHelp)
+	if r.message != "" {
+		fmt.Fprintf(w, "\n%s\n", r.message)
+	}
+}
+
+// readKey reads a key press. Arrow keys arrive as escape sequences at once,
+// a single escape cancels.
+func readKey(reader *bufio.Reader) (string, error) {
+	key, _, err := reader.ReadRune()
+	if err != nil {
+		return "", err
+	}
+
+	switch key {
+	case '\r', '\n':
+		return keyEnter, nil
+	case 0x7f, 0x08:
+		return keyBackspace, nil
+	case 0x03:
+		return keyCancel, nil
+	case 0x1b:
+		if reader.Buffered() == 0 {
+			return keyCancel, nil
+		}
+		if next, err := reader.Peek(1); err != nil || (next[0] != '[' && next[0] != 'O') {
+			return keyCancel, nil
+		}
+		reader.ReadRune()
+		code, _, err := reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch code {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		}
+		return "", nil
+	}
+
+	return string(key), nil
+}
+
+// prompt edits the value in place below the table. It returns false when the
+// edit was cancelled.
+func prompt(reader *bufio.Reader, w io.Writer, label, value string) (string, bool, error) {
+	runes := []rune(value)
+	for {
+		fmt.Fprintf(w, "\r\033[K%s: %s", label, string(runes))
+
+		key, err := readKey(reader)
+		if err != nil {
+			return "", false, err
+		}
+		switch key {
+		case keyEnter:
+			return strings.TrimSpace(string(runes)), true, nil
+		case keyCancel:
+			return "", false, nil
+		case keyBackspace:
+			if len(runes) > 0 {
+				runes = runes[:len(runes)-1]
+			}
+		case keyUp, keyDown, "":
+		default:
+			runes = append(runes, []rune(key)...)
+		}
+	}
+}
+
+// setComment changes the comment of the interval of the entry, as all its
+// parts share the comment.
+func (r *Review) setComment(index int, comment string) error {
+	if comment == "" {
+		return fmt.Errorf("the comment must not be empty")
+	}
+	for i := range r.Entries {
+		if r.Entries[i].ID == r.Entries[index].ID {
+			r.Entries[i].Comment = comment
+		}
+	}
+	return nil
+}
+
+// setActivity changes the activity of the interval of the entry. The
+// activity is given by ID or name and checked against the project when its
+// activities are known.
+func (r *Review) setActivity(index int, value string, activities []Activity) error {
+	activityID := ""
+	for _, activity := range activities {
+		if activity.ID == value || strings.EqualFold(activity.Tag, value) {
+			activityID = activity.ID
+		}
+	}
+	if activityID == "" && len(activities) > 0 {
+		return fmt.Errorf("activity %q is not available in the project", value)
+	}
+	if activityID == "" {
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("invalid activity ID %q", value)
+		}
+		activityID = value
+	}
+
+	for i := range r.Entries {
+		if r.Entries[i].ID == r.Entries[index].ID {
+			r.Entries[i].ActivityID = activityID
+		}
+	}
+	return nil
+}
+
+// setIssue replaces the issue of the entry in all parts of its interval, so
+// the portions of an interval crossing midnight stay on the same issue.
+func (r *Review) setIssue(index int, value string) error {
+	issueID, err := normalizeIssue(value)
+	if alias, ok := r.Aliases[value]; ok {
+		issueID, err = alias.Issue, nil
+	}
+	if err != nil {
+		return err
+	}
+	if !hasIssuePrefix(issueID, r.Prefix) {
+		return fmt.Errorf("%s is not an issue of this tracker", issueID)
+	}
+	if _, err := r.Resolver.Resolve(issueID); err != nil {
+		return err
+	}
+
+	entry := r.Entries[index]
+	old := r.issueOf(entry)
+	for i := range r.Entries {
+		if r.Entries[i].ID != entry.ID {
+			continue
+		}
+		for j, ID := range r.Entries[i].IssueIDs {
+			if ID == old {
+				r.Entries[i].IssueIDs[j] = issueID
+			}
+		}
+	}
+	return nil
+}
+
+// edit asks for the new comment, activity or issue of the selected entry.
+func (r *Review) edit(reader *bufio.Reader, w io.Writer, key string) error {
+	entry := r.Entries[r.cursor]
+
+	switch key {
+	case "c":
+		comment, ok, err := prompt(reader, w, "Comment", entry.Comment)
+		if err != nil || !ok {
+			return err
+		}
+		return r.setComment(r.cursor, comment)
+	case "a":
+		info, _ := r.Resolver.Resolve(r.issueOf(entry))
+		activities := r.projectActivities(info)
+		if len(activities) > 0 {
+			names := []string{}
+			for _, activity := range activities {
+				names = append(names, fmt.Sprintf("%s %s", activity.ID, activity.Tag))
+			}
+			r.message = "Activities: " + strings.Join(names, ", ")
+			r.render(w)
+			r.message = ""
+		}
+
+		activity, ok, err := prompt(reader, w, "Activity", entry.ActivityID)
+		if err != nil || !ok {
+			return err
+		}
+		return r.setActivity(r.cursor, activity, activities)
+	case "i":
+		issueID, ok, err := prompt(reader, w, "Issue", r.issueOf(entry))
+		if err != nil || !ok {
+			return err
+		}
+		return r.setIssue(r.cursor, issueID)
+	}
+
+	return nil
+}
+
+// ready checks that the included Redmine entries have an activity, which
+// Redmine requires.
+func (r *Review) ready() error {
+	if r.Prefix != "#" {
+		return nil
+	}
+	for index, entry := range r.Entries {
+		if !r.excluded[index] && entry.ActivityID == "" {
+			r.cursor = index
+			return fmt.Errorf("entry %d has no activity, please choose one with a", index)
+		}
+	}
+	return nil
+}
+
+// write stores the edits in the timewarrior tags and returns the entries to
+// push. The edits are written once per interval, which its parts share. The
+// activities are written in one batch per activity.
+func (r *Review) write() ([]TimeEntry, error) {
+	intervals := map[string]*TimeEntry{}
+	entries := []TimeEntry{}
+	activities, stale := map[string][]TimeEntry{}, map[string][]TimeEntry{}
+	for index, entry := range r.Entries {
+		original := r.original[index]
+
+		interval, ok := intervals[entry.ID]
+		if !ok {
+			interval = &original
+			intervals[entry.ID] = interval
+
+			if entry.Comment != original.Comment {
+				if err := interval.setComment(entry.Comment); err != nil {
+					return nil, err
+				}
+			}
+			if entry.ActivityID != original.ActivityID {
+				for _, tag := range interval.timewTags {
+					if strings.HasPrefix(tag, "A_") {
+						stale[tag] = append(stale[tag], *interval)
+					}
+				}
+				activities["A_"+entry.ActivityID] = append(activities["A_"+entry.ActivityID], *interval)
+			}
+		}
+
+		// the issue tag is replaced once for all portions of the interval
+		old, issueID := r.issueOf(original), r.issueOf(entry)
+		if old != issueID && interval.issueTagOf(old, r.Aliases) != "" {
+			if err := interval.replaceIssue(old, issueID, r.Aliases); err != nil {
+				return nil, err
+			}
+		}
+
+		if !r.excluded[index] {
+			entries = append(entries, entry)
+		}
+	}
+
+	if err := changeTagGroups("untag", stale); err != nil {
+		return nil, err
+	}
+	if err := changeTagGroups("tag", activities); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Run shows the review screen until the push is confirmed or cancelled. It
+// returns the entries to push, or nil when cancelled.
+func (r *Review) Run(in io.Reader, out io.Writer) ([]TimeEntry, error) {
+	// switch to the alternate screen buffer and back when done
+	fmt.Fprint(out, "\033[?1049h")
+	defer fmt.Fprint(out, "\033[?1049l")
+
+	reader := bufio.NewReader(in)
+	for {
+		r.render(out)
+
+		key, err := readKey(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		r.message = ""
+		switch key {
+		case keyUp, "k":
+			if r.cursor > 0 {
+				r.cursor--
+			}
+		case keyDown, "j":
+			if r.cursor < len(r.Entries)-1 {
+				r.cursor++
+			}
+		case "x":
+			r.excluded[r.cursor] = !r.excluded[r.cursor]
+		case "c", "a", "i":
+			if err := r.edit(reader, out, key); err != nil {
+				r.message = err.Error()
+			}
+		case "y":
+			if err := r.ready(); err != nil {
+				r.message = err.Error()
+				continue
+			}
+			return r.write()
+		case "q", keyCancel:
+			return nil, nil
+		}
+	}
+}
+
+// stty changes the mode of the terminal on stdin.
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// keyMode makes the terminal pass single key presses without echo. It
+// returns a function restoring the previous mode.
+func keyMode() (func(), error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return nil, err
+	}
+	return func() { stty(state) }, nil
+}
+
+func reviewEntries(entries []TimeEntry, prefix string, resolver *IssueResolver, aliases map[string]Alias, templates TemplateConfig) ([]TimeEntry, error) {
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+		restore, err := keyMode()
+		if err != nil {
+			return nil, fmt.Errorf("could not switch the terminal mode: %s", err)
+		}
+		defer restore()
+	}
+
+	review := newReview(entries, prefix, resolver, aliases)
+	review.Templates = templates
+	return review.Run(os.Stdin, os.Stdout)
+}
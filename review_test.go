@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReviewEditsInterval(t *testing.T) {
+	loc := localTime()
+	start := time.Date(2026, 10, 5, 22, 0, 0, 0, loc)
+	interval := TimeEntry{ID: "1", IssueIDs: []string{"#1"}, IsRedmine: true, Start: start, End: start.Add(4 * time.Hour), Hours: 4 * time.Hour}
+	other := TimeEntry{ID: "2", IssueIDs: []string{"#1"}, IsRedmine: true, ActivityID: "9", Start: start.Add(-2 * time.Hour), End: start, Hours: 2 * time.Hour}
+
+	resolver := &IssueResolver{Offline: true, cache: &IssueCache{Issues: map[string]IssueInfo{
+		"#1": {ID: "#1", Subject: "Old"},
+		"#2": {ID: "#2", Subject: "New"},
+	}}}
+	review := newReview(append(interval.splitAtMidnight(loc), other), "#", resolver, nil)
+
+	// select the second portion, change its issue and activity, then quit
+	keys := "\x1b[Bi\x7f\x7f#2\na9\nc\x1bq"
+	entries, err := review.Run(strings.NewReader(keys), io.Discard)
+	if err != nil || entries != nil {
+		t.Fatalf("expected the review to be cancelled, got %v %v", entries, err)
+	}
+
+	for _, entry := range review.Entries[:2] {
+		if entry.IssueIDs[0] != "#2" || entry.ActivityID != "9" {
+			t.Errorf("expected both portions on #2 with activity 9, got %v %s", entry.IssueIDs, entry.ActivityID)
+		}
+	}
+	if review.Entries[2].IssueIDs[0] != "#1" {
+		t.Errorf("expected the other interval to keep its issue, got %v", review.Entries[2].IssueIDs)
+	}
+	if review.Entries[0].Comment != "" {
+		t.Errorf("expected the cancelled comment edit to change nothing, got %q", review.Entries[0].Comment)
+	}
+	if review.original[1].IssueIDs[0] != "#1" {
+		t.Errorf("expected the original entries to be kept")
+	}
+}
+
+func TestReviewRequiresActivity(t *testing.T) {
+	resolver := &IssueResolver{Offline: true, cache: &IssueCache{Issues: map[string]IssueInfo{}}}
+	review := newReview([]TimeEntry{
+		{ID: "1", IssueIDs: []string{"#1"}, ActivityID: "9"},
+		{ID: "2", IssueIDs: []string{"#1"}},
+	}, "#", resolver, nil)
+
+	if err := review.ready(); err == nil || review.cursor != 1 {
+		t.Errorf("expected entry 1 without activity to be selected, got %v", err)
+	}
+
+	review.excluded[1] = true
+	if err := review.ready(); err != nil {
+		t.Errorf("expected excluded entries not to need an activity, got %s", err)
+	}
+
+	if err := review.setActivity(0, "Development", []Activity{{ID: "9", Tag: "Development"}}); err != nil || review.Entries[0].ActivityID != "9" {
+		t.Errorf("expected the activity to be found by name, got %v", err)
+	}
+	if err := review.setActivity(0, "12", []Activity{{ID: "9", Tag: "Development"}}); err == nil {
+		t.Errorf("expected an error for an activity outside the project")
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Rule assigns issues to entries without ticket tags. All given conditions
+// must match: the entry carries all Tags, the comment matches the Comment
+// regex and the entry starts between From and To (`15:04`, To exclusive).
+type Rule struct {
+	Name    string   `json:"name"`
+	Tags    []string `json:"tags"`
+	Comment string   `json:"comment"`
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+
+	// Redmine is the issue number, Jira the issue key like `PIM-1200`.
+	Redmine  string `json:"redmine"`
+	Activity string `json:"activity"`
+	Jira     string `json:"jira"`
+
+	comment *regexp.Regexp
+}
+
+func compileRules(rules []Rule) ([]Rule, error) {
+	compiled := []Rule{}
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+
+		if rule.Redmine == "" && rule.Jira == "" {
+			return nil, fmt.Errorf("%s: neither redmine nor jira issue given", rule.Name)
+		}
+
+		if rule.Comment != "" {
+			rexp, err := regexp.Compile(rule.Comment)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", rule.Name, err)
+			}
+			rule.comment = rexp
+		}
+
+		for _, t := range []string{rule.From, rule.To} {
+			if t == "" {
+				continue
+			}
+			if _, err := time.Parse("15:04", t); err != nil {
+				return nil, fmt.Errorf("%s: invalid time of day %q", rule.Name, t)
+			}
+		}
+
+		compiled = append(compiled, rule)
+	}
+
+	return compiled, nil
+}
+
+func (r Rule) matches(te TimeEntry) bool {
+	for _, tag := range r.Tags {
+		if !te.hasTag(tag) {
+			return false
+		}
+	}
+
+	if r.comment != nil && !r.comment.MatchString(te.Comment) {
+		return false
+	}
+
+	start := te.Start.In(localTime()).Format("15:04")
+	if r.From != "" && start < r.From {
+		return false
+	}
+	if r.To != "" && start >= r.To {
+		return false
+	}
+
+	return true
+}
+
+func (r Rule) apply(te *TimeEntry) {
+	te.Rule = r.Name
+
+	if r.Redmine != "" {
+		te.IsRedmine = true
+		te.IssueIDs = append(te.IssueIDs, "#"+strings.TrimPrefix(r.Redmine, "#"))
+		if te.ActivityID == "" {
+			te.ActivityID = r.Activity
+		}
+	}
+
+	if r.Jira != "" {
+		te.IsJira = true
+		te.IssueIDs = append(te.IssueIDs, r.Jira)
+	}
+}
+
+// applyRules assigns the issues of the first matching rule to every entry
+// which has no ticket tags.
+func (el *EntryList) applyRules(rules []Rule) error {
+	compiled, err := compileRules(rules)
+	if err != nil {
+		return err
+	}
+
+	for i := range el.Entries {
+		if len(el.Entries[i].IssueIDs) > 0 {
+			continue
+		}
+
+		for _, rule := range compiled {
+			if rule.matches(el.Entries[i]) {
+				rule.apply(&el.Entries[i])
+				break
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompileRules(t *testing.T) {
+	rules, err := compileRules([]Rule{{Redmine: "100"}, {Name: "standup", Jira: "PIM-7"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if rules[0].Name != "rule 1" || rules[1].Name != "standup" {
+		t.Errorf("Expected the default name for unnamed rules, got %q and %q", rules[0].Name, rules[1].Name)
+	}
+
+	invalid := map[string]Rule{
+		"no issue":      {Name: "empty"},
+		"invalid regex": {Redmine: "100", Comment: "(fix"},
+		"invalid from":  {Redmine: "100", From: "9 am"},
+		"invalid to":    {Redmine: "100", To: "25:00"},
+	}
+	for name, rule := range invalid {
+		if _, err := compileRules([]Rule{rule}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	// 09:30 in local time, 07:30 UTC
+	start := time.Date(2026, 10, 5, 9, 30, 0, 0, localTime())
+	entry := TimeEntry{Start: start, End: start.Add(time.Hour), Comment: "daily standup", Tags: []string{"meeting", "team"}}
+
+	tests := []struct {
+		rule     Rule
+		expected bool
+	}{
+		{Rule{Redmine: "1", Tags: []string{"meeting"}}, true},
+		{Rule{Redmine: "1", Tags: []string{"meeting", "customer"}}, false},
+		{Rule{Redmine: "1", Comment: "(?i)^DAILY"}, true},
+		{Rule{Redmine: "1", Comment: "review"}, false},
+		{Rule{Redmine: "1", From: "09:00", To: "10:00"}, true},
+		{Rule{Redmine: "1", From: "09:30"}, true},
+		{Rule{Redmine: "1", To: "09:30"}, false},
+		{Rule{Redmine: "1", From: "07:00", To: "08:00"}, false},
+	}
+	for _, test := range tests {
+		rules, err := compileRules([]Rule{test.rule})
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err)
+		}
+		if matches := rules[0].matches(entry); matches != test.expected {
+			t.Errorf("Expected %v for %+v, got %v", test.expected, test.rule, matches)
+		}
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	start := time.Date(2026, 10, 5, 9, 30, 0, 0, localTime())
+	el := EntryList{Entries: []TimeEntry{
+		{ID: "3", Start: start, Tags: []string{"meeting"}, Comment: "daily standup"},
+		{ID: "2", Start: start, Tags: []string{"meeting"}, IssueIDs: []string{"#5"}, IsRedmine: true},
+		{ID: "1", Start: start, Comment: "lunch"},
+	}}
+	rules := []Rule{
+		{Name: "standup", Tags: []string{"meeting"}, Comment: "standup", Redmine: "#100", Activity: "9", Jira: "PIM-7"},
+		{Name: "meetings", Tags: []string{"meeting"}, Redmine: "200"},
+	}
+
+	if err := el.applyRules(rules); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	// the first matching rule wins
+	first := el.Entries[0]
+	if first.Rule != "standup" || len(first.IssueIDs) != 2 || first.IssueIDs[0] != "#100" || first.IssueIDs[1] != "PIM-7" || first.ActivityID != "9" || !first.IsRedmine || !first.IsJira {
+		t.Errorf("Expected the standup rule, got %+v", first)
+	}
+	// entries with issues keep them
+	if second := el.Entries[1]; second.Rule != "" || len(second.IssueIDs) != 1 || second.IssueIDs[0] != "#5" {
+		t.Errorf("Expected the entry with an issue to be skipped, got %+v", second)
+	}
+	if third := el.Entries[2]; third.Rule != "" || len(third.IssueIDs) != 0 {
+		t.Errorf("Expected no rule to match, got %+v", third)
+	}
+
+	if err := el.applyRules([]Rule{{Redmine: "1", Comment: "["}}); err == nil {
+		t.Errorf("Expected an error for an invalid rule")
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/onpremise"
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+const searchLimit = 25
+
+// searchRedmine finds open issues whose subject contains the text. With mine
+// only the issues assigned to the current user are returned.
+func (r *IssueResolver) searchRedmine(text string, mine bool) ([]IssueInfo, error) {
+	api, err := r.Redmine.getApi()
+	if err != nil {
+		return nil, err
+	}
+
+	filters := redmine.IssueGetRequestFiltersInit().FieldAdd("status_id", "open")
+	if text != "" {
+		filters.FieldAdd("subject", "~"+text)
+	}
+	if mine {
+		filters.FieldAdd("assigned_to_id", "me")
+	}
+
+	result, code, err := api.IssuesMultiGet(redmine.IssueMultiGetRequest{
+		Filters: filters,
+		Limit:   searchLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("error searching Redmine: %d", code)
+	}
+
+	issues := []IssueInfo{}
+	for _, issue := range result.Issues {
+		issues = append(issues, IssueInfo{
+			ID:        "#" + strconv.FormatInt(issue.ID, 10),
+			Subject:   issue.Subject,
+			Project:   issue.Project.Name,
+			ProjectID: strconv.FormatInt(issue.Project.ID, 10),
+			Status:    issue.Status.Name,
+		})
+	}
+
+	return issues, nil
+}
+
+// searchJira finds unresolved issues whose summary contains the text.
+func (r *IssueResolver) searchJira(text string, mine bool) ([]IssueInfo, error) {
+	if r.jiraClient == nil {
+		client, err := r.Jira.getJiraClient()
+		if err != nil {
+			return nil, err
+		}
+		r.jiraClient = client
+	}
+
+	conditions := []string{"resolution = Unresolved"}
+	if text != "" {
+		conditions = append(conditions, fmt.Sprintf("summary ~ %q", text))
+	}
+	if mine {
+		conditions = append(conditions, "assignee = currentUser()")
+	}
+	jql := strings.Join(conditions, " AND ") + " ORDER BY updated DESC"
+
+	result, _, err := r.jiraClient.Issue.Search(context.Background(), jql, &jira.SearchOptions{
+		MaxResults: searchLimit,
+		Fields:     []string{"summary", "project", "status"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	issues := []IssueInfo{}
+	for _, issue := range result {
+		if !hasIssuePrefix(issue.Key, r.Jira.TicketPrefix) {
+			continue
+		}
+
+		info := IssueInfo{ID: issue.Key}
+		if issue.Fields != nil {
+			info.Subject = issue.Fields.Summary
+			info.Project = issue.Fields.Project.Name
+			info.ProjectID = issue.Fields.Project.Key
+			if issue.Fields.Status != nil {
+				info.Status = issue.Fields.Status.Name
+			}
+		}
+		issues = append(issues, info)
+	}
+
+	return issues, nil
+}
+
+// Search queries all configured trackers. The results are added to the cache.
+func (r *IssueResolver) Search(text string, mine bool) ([]IssueInfo, error) {
+	if r.Redmine == nil && r.Jira == nil {
+		return nil, fmt.Errorf("neither Redmine nor JIRA are configured")
+	}
+
+	issues := []IssueInfo{}
+	if r.Redmine != nil {
+		found, err := r.searchRedmine(text, mine)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+	}
+	if r.Jira != nil {
+		found, err := r.searchJira(text, mine)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+	}
+
+	for _, issue := range issues {
+		issue.Fetched = time.Now()
+		r.cache.Issues[issue.ID] = issue
+	}
+
+	return issues, r.Save()
+}
+
+func writeIssues(issues []IssueInfo, numbered bool) {
+	table := tablewriter.NewWriter(os.Stdout)
+	header := []string{"Issue", "Subject", "Project", "Status"}
+	if numbered {
+		header = append([]string{"#"}, header...)
+	}
+	table.SetHeader(header)
+
+	for index, issue := range issues {
+		row := []string{issue.ID, issue.Subject, issue.Project, issue.Status}
+		if numbered {
+			row = append([]string{strconv.Itoa(index)}, row...)
+		}
+		table.Append(row)
+	}
+
+	table.Render()
+}
+
+// promptNumber asks for a number between 0 and max (exclusive).
+func promptNumber(question string, max int) (int, error) {
+	var input string
+	fmt.Printf("%s: ", question)
+	fmt.Scanf("%s", &input)
+
+	selected, err := strconv.Atoi(input)
+	if err != nil {
+		return 0, err
+	}
+	if selected < 0 || selected >= max {
+		return 0, fmt.Errorf("invalid selection %d", selected)
+	}
+
+	return selected, nil
+}
+
+func issuesCommand() cli.Command {
+	return cli.Command{
+		Name:  "issues",
+		Usage: "Find issues in Redmine and JIRA.",
+		Subcommands: []cli.Command{
+			{
+				Name:      "search",
+				Usage:     "Search open issues by their subject.",
+				ArgsUsage: "[text]",
+				Flags: append(append([]cli.Flag{
+					&cli.BoolFlag{
+						Name:  "mine",
+						Usage: "Only show issues assigned to me.",
+					},
+					&cli.BoolFlag{
+						Name:  "select",
+						Usage: "Select an issue and start tracking it, or tag an interval with it.",
+					},
+					&cli.StringFlag{
+						Name:  "comment",
+						Usage: "The comment used when tracking the selected issue.",
+					},
+					&cli.StringFlag{
+						Name:  "interval",
+						Usage: "Tag the interval with this ID instead of starting to track.",
+					},
+				}, redmineFlags()...), jiraFlags()...),
+				Action: func(ctx *cli.Context) error {
+					text := strings.Join(ctx.Args(), " ")
+					if text == "" && !ctx.Bool("mine") {
+						return fmt.Errorf("please give a text to search for or use --mine")
+					}
+
+					// the interval is looked up first, so the tag change is journaled
+					var interval *TimeEntry
+					if ID := strings.TrimPrefix(ctx.String("interval"), "@"); ID != "" {
+						el := EntryList{raw: true, running: true}
+						if err := el.fromTimeWarrior("all"); err != nil {
+							return err
+						}
+						for i := range el.Entries {
+							if el.Entries[i].ID == ID {
+								interval = &el.Entries[i]
+							}
+						}
+						if interval == nil {
+							return fmt.Errorf("interval @%s not found", ID)
+						}
+					}
+
+					resolver, err := newIssueResolver(ctx)
+					if err != nil {
+						return err
+					}
+
+					issues, err := resolver.Search(text, ctx.Bool("mine"))
+					if err != nil {
+						return err
+					}
+					if len(issues) == 0 {
+						fmt.Println("No issues found.")
+						return nil
+					}
+
+					writeIssues(issues, ctx.Bool("select"))
+					if !ctx.Bool("select") {
+						return nil
+					}
+
+					selected, err := promptNumber("Please enter the number of the issue", len(issues))
+					if err != nil {
+						return err
+					}
+					tag := issueTag(issues[selected].ID)
+
+					if interval != nil {
+						return interval.retag(nil, []string{tag})
+					}
+
+					args := []string{"start", tag}
+					if ctx.String("comment") != "" {
+						args = append(args, commentTagFor(ctx.String("comment")))
+					}
+					return timew(args...)
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+// Selector picks the intervals for bulk commands. All given criteria have to
+// match.
+type Selector struct {
+	// Issues are glob patterns like `#12*` or `PIM-*`. A single `*` matches
+	// every interval, also the ones without issues.
+	Issues       []string
+	HasTags      []string
+	CommentRegex *regexp.Regexp
+	IDs          map[string]bool
+}
+
+// issuePattern converts the tag notation of an issue pattern, e.g. `R_12*`
+// to `#12*`.
+func issuePattern(pattern string) string {
+	switch {
+	case strings.HasPrefix(pattern, "R_"):
+		return "#" + strings.TrimPrefix(pattern, "R_")
+	case strings.HasPrefix(pattern, "J_") && strings.Contains(pattern, "-"):
+		return strings.TrimPrefix(pattern, "J_")
+	case strings.HasPrefix(pattern, "J_"):
+		return "PIM-" + strings.TrimPrefix(pattern, "J_")
+	}
+	return pattern
+}
+
+func newSelector(ctx *cli.Context) (*Selector, error) {
+	selector := &Selector{IDs: map[string]bool{}}
+
+	for _, pattern := range ctx.StringSlice("issue") {
+		pattern = issuePattern(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid issue pattern %q: %s", pattern, err)
+		}
+		selector.Issues = append(selector.Issues, pattern)
+	}
+	selector.HasTags = ctx.StringSlice("has-tag")
+
+	if ctx.String("comment-regex") != "" {
+		rexp, err := regexp.Compile(ctx.String("comment-regex"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid comment regex: %s", err)
+		}
+		selector.CommentRegex = rexp
+	}
+
+	for _, ID := range ctx.StringSlice("id") {
+		selector.IDs[strings.TrimPrefix(ID, "@")] = true
+	}
+
+	if len(selector.Issues) == 0 && len(selector.HasTags) == 0 && selector.CommentRegex == nil && len(selector.IDs) == 0 {
+		return nil, fmt.Errorf("please select the intervals with --issue, --has-tag, --comment-regex or --id")
+	}
+
+	return selector, nil
+}
+
+func (s Selector) matches(te TimeEntry) bool {
+	if len(s.IDs) > 0 && !s.IDs[te.ID] {
+		return false
+	}
+
+	for _, tag := range s.HasTags {
+		found := false
+		for _, t := range te.timewTags {
+			found = found || t == tag
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if s.CommentRegex != nil && !s.CommentRegex.MatchString(te.Comment) {
+		return false
+	}
+
+	if len(s.Issues) == 0 {
+		return true
+	}
+	for _, pattern := range s.Issues {
+		if pattern == "*" {
+			return true
+		}
+		for _, issueID := range te.IssueIDs {
+			if ok, _ := path.Match(pattern, issueID); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (el *EntryList) selected(s Selector) []TimeEntry {
+	entries := []TimeEntry{}
+	for _, entry := range el.Entries {
+		if s.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// loadSelection exports the intervals given by --range or --from and --to.
+// The intervals are kept as stored in timewarrior, as tags belong to them.
+func (el *EntryList) loadSelection(ctx *cli.Context) error {
+	el.raw = true
+
+	if ctx.String("from") == "" && ctx.String("to") == "" {
+		time_range := ctx.String("range")
+		if time_range != "all" && time_range != "month" && time_range != "week" && time_range != "day" {
+			return fmt.Errorf("invalid time range, please use 'all', 'month', 'week', or 'day'")
+		}
+		return el.fromTimeWarrior(time_range)
+	}
+
+	if ctx.IsSet("range") {
+		return fmt.Errorf("please give either --range or --from and --to")
+	}
+
+	loc := localTime()
+	from := time.Date(1970, 1, 1, 0, 0, 0, 0, loc)
+	to := time.Now()
+	if ctx.String("from") != "" {
+		day, err := time.ParseInLocation("2006-01-02", ctx.String("from"), loc)
+		if err != nil {
+			return fmt.Errorf("invalid --from date: %s", err)
+		}
+		from = day
+	}
+	if ctx.String("to") != "" {
+		day, err := time.ParseInLocation("2006-01-02", ctx.String("to"), loc)
+		if err != nil {
+			return fmt.Errorf("invalid --to date: %s", err)
+		}
+		// the end day is included
+		to = day.AddDate(0, 0, 1)
+	}
+
+	return el.fromTimeWarriorInterval(from, to)
+}
+
+func selectionFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "range",
+			Value: "all",
+			Usage: "The time range to select from. Valid ranges are 'all', 'month', 'week', and 'day'.",
+		},
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: "Select intervals from this day on (YYYY-MM-DD).",
+		},
+		&cli.StringFlag{
+			Name:  "to",
+			Usage: "Select intervals up to and including this day (YYYY-MM-DD).",
+		},
+		&cli.StringSliceFlag{
+			Name:  "issue, issueID",
+			Usage: "Select intervals with an issue matching the pattern, e.g. '#123', 'R_12*' or '*'. Can be repeated.",
+		},
+		&cli.StringSliceFlag{
+			Name:  "has-tag",
+			Usage: "Select intervals with this tag. Can be repeated.",
+		},
+		&cli.StringFlag{
+			Name:  "comment-regex",
+			Usage: "Select intervals whose comment matches the regular expression.",
+		},
+		&cli.StringSliceFlag{
+			Name:  "id",
+			Usage: "Select the interval with this ID. Can be repeated.",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Only print the selected intervals.",
+		},
+	}
+}
+
+func writeSelection(entries []TimeEntry) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Date", "Hours", "Issues", "Tags", "Comment"})
+
+	loc := localTime()
+	for _, entry := range entries {
+		table.Append([]string{
+			entry.ID,
+			entry.Start.In(loc).Format("2006-01-02 15:04"),
+			fmt.Sprintf("%.2f", entry.Hours.Hours()),
+			strings.Join(entry.IssueIDs, ", "),
+			strings.Join(entry.Tags, ", "),
+			entry.Comment,
+		})
+	}
+	table.Render()
+}
+
+// markCommand builds the tag and untag commands, which only differ in the
+// timewarrior command applied to the selected intervals.
+func markCommand(name, usage string) cli.Command {
+	return cli.Command{
+		Name:  name,
+		Usage: usage,
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "tag",
+				Usage: "The tag to " + name,
+			},
+		}, selectionFlags()...),
+		Action: func(ctx *cli.Context) error {
+			aliases, err := aliasMap()
+			if err != nil {
+				return err
+			}
+
+			tag := ctx.String("tag")
+			if tag == "" {
+				return fmt.Errorf("please give the --tag")
+			}
+
+			selector, err := newSelector(ctx)
+			if err != nil {
+				return err
+			}
+
+			el := EntryList{Aliases: aliases}
+			if err := el.loadSelection(ctx); err != nil {
+				return err
+			}
+
+			entries := el.selected(*selector)
+			writeSelection(entries)
+			if ctx.Bool("dry-run") || len(entries) == 0 {
+				return nil
+			}
+
+			return changeTags(name, entries, []string{tag})
+		},
+	}
+}
+
+func tagCommand() cli.Command {
+	return markCommand("tag", "Set a tag to the selected entries")
+}
+
+func untagCommand() cli.Command {
+	return markCommand("untag", "Remove a tag from the selected entries")
+}
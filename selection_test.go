@@ -0,0 +1,56 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSelectorMatches(t *testing.T) {
+	entries := []TimeEntry{}
+	for _, entry := range []TimeWarriorEntry{
+		{ID: 1, Start: "20261005T080000Z", End: "20261005T090000Z", Tags: []string{"R_123", "S2R", "fixed the login"}},
+		{ID: 2, Start: "20261005T090000Z", End: "20261005T100000Z", Tags: []string{"R_1299", "J_5", "planned the release"}},
+		{ID: 3, Start: "20261005T100000Z", End: "20261005T110000Z", Tags: []string{"meeting"}},
+	} {
+		te, err := parse(entry, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err)
+		}
+		entries = append(entries, *te)
+	}
+
+	tests := []struct {
+		name     string
+		selector Selector
+		expected []string
+	}{
+		{"exact issue", Selector{Issues: []string{"#123"}}, []string{"1"}},
+		{"issue glob", Selector{Issues: []string{issuePattern("R_12*")}}, []string{"1", "2"}},
+		{"several issues", Selector{Issues: []string{"#123", "PIM-*"}}, []string{"1", "2"}},
+		{"wildcard", Selector{Issues: []string{"*"}}, []string{"1", "2", "3"}},
+		{"tag", Selector{HasTags: []string{"S2R"}}, []string{"1"}},
+		{"comment", Selector{CommentRegex: regexp.MustCompile("^plan")}, []string{"2"}},
+		{"id and issue", Selector{Issues: []string{"*"}, IDs: map[string]bool{"3": true}}, []string{"3"}},
+		{"no match", Selector{Issues: []string{"#12"}}, []string{}},
+	}
+
+	for _, test := range tests {
+		el := EntryList{Entries: entries}
+		selected := el.selected(test.selector)
+
+		IDs := []string{}
+		for _, entry := range selected {
+			IDs = append(IDs, entry.ID)
+		}
+		if len(IDs) != len(test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, IDs)
+			continue
+		}
+		for i := range IDs {
+			if IDs[i] != test.expected[i] {
+				t.Errorf("%s: expected %v, got %v", test.name, test.expected, IDs)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+// Status is the running interval and the time tracked today.
+type Status struct {
+	Running *TimeEntry
+	Today   time.Duration
+}
+
+// status finds the running interval and sums the entries of the day. Only
+// the part of an interval crossing midnight within the day is counted.
+func (el *EntryList) status(day time.Time) Status {
+	status := Status{}
+	date := day.Format("2006-01-02")
+	for i, entry := range el.Entries {
+		if entry.Running {
+			status.Running = &el.Entries[i]
+		}
+		for _, part := range entry.splitAtMidnight(day.Location()) {
+			if part.Start.In(day.Location()).Format("2006-01-02") == date {
+				status.Today += part.Hours
+			}
+		}
+	}
+	return status
+}
+
+func (s Status) write(w io.Writer, resolver *IssueResolver) {
+	if s.Running == nil {
+		fmt.Fprintln(w, "Not tracking.")
+	} else {
+		entry := s.Running
+		fmt.Fprintf(w, "Tracking @%s since %s (%s)\n", entry.ID, entry.Start.In(localTime()).Format("15:04"), formatDuration(entry.End.Sub(entry.Start)))
+
+		for _, issueID := range entry.IssueIDs {
+			info, _ := resolver.Resolve(issueID)
+			line := issueID
+			if info.Subject != "" {
+				line += ": " + info.Subject
+			}
+			if info.Project != "" {
+				line += " (" + info.Project + ")"
+			}
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+		if len(entry.IssueIDs) == 0 {
+			fmt.Fprintln(w, "  no issue")
+		}
+		if entry.Comment != "" {
+			fmt.Fprintf(w, "  %s\n", entry.Comment)
+		}
+		if len(entry.Tags) > 0 {
+			fmt.Fprintf(w, "  tags: %s\n", strings.Join(entry.Tags, ", "))
+		}
+	}
+
+	fmt.Fprintf(w, "Today: %s\n", formatDuration(s.Today))
+}
+
+func statusCommand() cli.Command {
+	return cli.Command{
+		Name:  "status",
+		Usage: "Show the running interval and the time tracked today.",
+		Flags: append(append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "offline",
+				Usage: "Only use the local issue cache to look up the issues.",
+			},
+		}, redmineFlags()...), jiraFlags()...),
+		Action: func(ctx *cli.Context) error {
+			aliases, err := aliasMap()
+			if err != nil {
+				return err
+			}
+
+			// the running interval may have started before today
+			now := time.Now().In(localTime())
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+			el := EntryList{Aliases: aliases, raw: true, running: true}
+			if err := el.fromOwnRange("status", today.AddDate(0, 0, -1), today.AddDate(0, 0, 1)); err != nil {
+				return err
+			}
+
+			resolver, err := newIssueResolver(ctx)
+			if err != nil {
+				return err
+			}
+
+			el.status(today).write(os.Stdout, resolver)
+			return resolver.Save()
+		},
+	}
+}
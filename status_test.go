@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryListStatus(t *testing.T) {
+	loc := localTime()
+	day := time.Date(2026, 10, 6, 0, 0, 0, 0, loc)
+
+	el := EntryList{Entries: []TimeEntry{
+		{ID: "3", Start: day.Add(-time.Hour), End: day.Add(time.Hour), Hours: 2 * time.Hour},
+		{ID: "2", Start: day.Add(8 * time.Hour), End: day.Add(10 * time.Hour), Hours: 2 * time.Hour},
+		{ID: "1", Start: day.Add(11 * time.Hour), End: day.Add(11*time.Hour + 30*time.Minute), Hours: 30 * time.Minute, Running: true},
+	}}
+
+	status := el.status(day)
+	if status.Running == nil || status.Running.ID != "1" {
+		t.Fatalf("Expected @1 to be running, got %+v", status.Running)
+	}
+	if status.Today != 3*time.Hour+30*time.Minute {
+		t.Errorf("Expected 3:30 today, got %s", formatDuration(status.Today))
+	}
+
+	el.Entries = el.Entries[:2]
+	if status := el.status(day); status.Running != nil {
+		t.Errorf("Expected no running interval, got %+v", status.Running)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// partKey identifies a part of an interval which is pushed on its own, as
+// intervals are split at midnight and across the issues of a tracker.
+func (te *TimeEntry) partKey(prefix string) string {
+	issueID := ""
+	if issueIDs := te.issuesWithPrefix(prefix); len(issueIDs) > 0 {
+		issueID = issueIDs[0]
+	}
+	return issueID + "@" + te.Start.In(localTime()).Format("2006-01-02")
+}
+
+// partMarker marks a pushed part of an interval, e.g. `S2R:#123@2026-10-05`.
+func partMarker(marker, key string) string {
+	return marker + ":" + key
+}
+
+// hasSyncMarker reports whether the interval or any of its parts was pushed.
+func (te *TimeEntry) hasSyncMarker(marker string) bool {
+	for _, tag := range te.Tags {
+		if tag == marker || strings.HasPrefix(tag, marker+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// SyncState tracks the pushed parts of the intervals. An interval is only
+// marked as synced once all of its parts are pushed; until then each pushed
+// part carries its own marker, so a failed part is pushed again on the next
+// run without duplicating the others.
+type SyncState struct {
+	Marker string
+	Prefix string
+
+	parts     map[string][]string
+	intervals map[string]TimeEntry
+	pushed    map[string]map[string]bool
+	// running is the running interval up to the time it was read.
+	running *TimeEntry
+}
+
+// newSyncState collects the parts of all intervals, including the ones which
+// are not pushed in this run.
+func newSyncState(marker, prefix string, parts []TimeEntry) *SyncState {
+	s := &SyncState{
+		Marker:    marker,
+		Prefix:    prefix,
+		parts:     map[string][]string{},
+		intervals: map[string]TimeEntry{},
+		pushed:    map[string]map[string]bool{},
+	}
+	for _, part := range parts {
+		if _, ok := s.intervals[part.ID]; !ok {
+			s.intervals[part.ID] = part
+		}
+		s.parts[part.ID] = append(s.parts[part.ID], part.partKey(prefix))
+
+		if part.Running && (s.running == nil || part.End.After(s.running.End)) {
+			running := part
+			s.running = &running
+		}
+	}
+	return s
+}
+
+// synced reports whether the part was pushed before.
+func (s *SyncState) synced(part TimeEntry) bool {
+	return part.hasTag(s.Marker) || part.hasTag(partMarker(s.Marker, part.partKey(s.Prefix)))
+}
+
+// pending returns the parts which were not pushed before.
+func (s *SyncState) pending(parts []TimeEntry) []TimeEntry {
+	result := []TimeEntry{}
+	for _, part := range parts {
+		if !s.synced(part) {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// done records a successfully pushed part.
+func (s *SyncState) done(part TimeEntry) {
+	if s.pushed[part.ID] == nil {
+		s.pushed[part.ID] = map[string]bool{}
+	}
+	s.pushed[part.ID][part.partKey(s.Prefix)] = true
+}
+
+// changes returns the intervals to tag and untag per tag: complete intervals
+// get the marker instead of their part markers, the others the markers of
+// the parts pushed in this run.
+func (s *SyncState) changes() (map[string][]TimeEntry, map[string][]TimeEntry) {
+	tag, untag := map[string][]TimeEntry{}, map[string][]TimeEntry{}
+	for ID, pushed := range s.pushed {
+		interval := s.intervals[ID]
+
+		complete := true
+		for _, key := range s.parts[ID] {
+			complete = complete && (pushed[key] || interval.hasTag(partMarker(s.Marker, key)))
+		}
+
+		if complete {
+			tag[s.Marker] = append(tag[s.Marker], interval)
+			for _, key := range s.parts[ID] {
+				if marker := partMarker(s.Marker, key); interval.hasTag(marker) {
+					untag[marker] = append(untag[marker], interval)
+				}
+			}
+			continue
+		}
+
+		for key := range pushed {
+			marker := partMarker(s.Marker, key)
+			tag[marker] = append(tag[marker], interval)
+		}
+	}
+	return tag, untag
+}
+
+// write tags the intervals in timewarrior, batching the intervals with the
+// same marker. A pushed running interval is continued as a new interval, so
+// the time tracked afterwards is not marked as synced.
+func (s *SyncState) write() error {
+	tag, untag := s.changes()
+	if err := changeTagGroups("tag", tag); err != nil {
+		return fmt.Errorf("could not mark the pushed entries: %s", err)
+	}
+	if err := changeTagGroups("untag", untag); err != nil {
+		return fmt.Errorf("could not mark the pushed entries: %s", err)
+	}
+
+	if s.running != nil && len(s.pushed[s.running.ID]) > 0 {
+		return s.running.continueAt(s.running.End)
+	}
+	return nil
+}
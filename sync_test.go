@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSyncState(t *testing.T) {
+	loc := localTime()
+	start := time.Date(2026, 10, 5, 22, 0, 0, 0, loc)
+	interval := TimeEntry{
+		ID:       "1",
+		IssueIDs: []string{"#100", "#200"},
+		Start:    start,
+		End:      start.Add(4 * time.Hour),
+		Hours:    4 * time.Hour,
+		Weights:  map[string]float64{"#100": 70, "#200": 30},
+	}
+
+	// two days with two issues each
+	parts := []TimeEntry{}
+	for _, portion := range interval.splitAtMidnight(loc) {
+		distributed, err := portion.distribute("#")
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, distributed...)
+	}
+	if len(parts) != 4 {
+		t.Fatalf("expected 4 parts, got %d", len(parts))
+	}
+
+	state := newSyncState("S2R", "#", parts)
+	state.done(parts[0])
+	state.done(parts[1])
+
+	tag, untag := state.changes()
+	if len(tag["S2R"]) != 0 || len(untag) != 0 {
+		t.Errorf("expected the incomplete interval not to be marked, got %v %v", tag, untag)
+	}
+	if len(tag["S2R:#100@2026-10-05"]) != 1 || len(tag["S2R:#200@2026-10-05"]) != 1 || len(tag) != 2 {
+		t.Errorf("expected the markers of the pushed parts, got %v", tag)
+	}
+
+	// the next run only pushes the missing parts
+	for i := range parts {
+		parts[i].Tags = append(parts[i].Tags, "S2R:#100@2026-10-05", "S2R:#200@2026-10-05")
+	}
+	state = newSyncState("S2R", "#", parts)
+	pending := state.pending(parts)
+	if len(pending) != 2 || pending[0].partKey("#") != "#100@2026-10-06" {
+		t.Fatalf("expected the parts of the second day to be pending, got %d", len(pending))
+	}
+	for _, part := range pending {
+		state.done(part)
+	}
+
+	tag, untag = state.changes()
+	if len(tag) != 1 || len(tag["S2R"]) != 1 {
+		t.Errorf("expected the complete interval to be marked, got %v", tag)
+	}
+	if len(untag) != 2 || len(untag["S2R:#100@2026-10-05"]) != 1 {
+		t.Errorf("expected the part markers to be removed, got %v", untag)
+	}
+
+	parts[0].Tags = []string{"S2R"}
+	if !newSyncState("S2R", "#", parts).synced(parts[0]) {
+		t.Errorf("expected a marked interval to be synced")
+	}
+}
+
+func TestSyncStateRunning(t *testing.T) {
+	loc := localTime()
+	start := time.Date(2026, 10, 5, 22, 0, 0, 0, loc)
+	interval := TimeEntry{
+		ID:       "1",
+		IssueIDs: []string{"#100"},
+		Start:    start,
+		End:      start.Add(3 * time.Hour),
+		Hours:    3 * time.Hour,
+		Running:  true,
+	}
+
+	// the running interval is continued at the end of its last portion
+	state := newSyncState("S2R", "#", interval.splitAtMidnight(loc))
+	if state.running == nil || !state.running.End.Equal(interval.End) {
+		t.Fatalf("expected the running interval up to %s, got %+v", interval.End, state.running)
+	}
+}
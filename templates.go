@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+)
+
+// redmineCommentLength is the longest comment Redmine accepts.
+const redmineCommentLength = 1024
+
+// SinkTemplate builds the comment pushed to one tracker.
+type SinkTemplate struct {
+	// Comment is a text/template over CommentData, `{{.Comment}}` by default.
+	Comment string `json:"comment"`
+	// Fallback is used when Comment renders empty.
+	Fallback string `json:"fallback"`
+	// MaxLength truncates longer comments; 0 means no limit, except for
+	// Redmine.
+	MaxLength int `json:"maxLength"`
+}
+
+type TemplateConfig struct {
+	Redmine SinkTemplate `json:"redmine"`
+	Jira    SinkTemplate `json:"jira"`
+}
+
+// CommentData is passed to the templates. Tags and Hours are formatted for
+// comments, the other fields are the ones of the entry.
+type CommentData struct {
+	TimeEntry
+	Tags         string
+	Hours        string
+	Date         string
+	Issue        string
+	IssueSubject string
+	IssueProject string
+}
+
+// usesIssues reports whether the template refers to the details of the
+// issue, which have to be looked up in the tracker.
+func (st SinkTemplate) usesIssues() bool {
+	for _, text := range []string{st.Comment, st.Fallback} {
+		if strings.Contains(text, ".IssueSubject") || strings.Contains(text, ".IssueProject") {
+			return true
+		}
+	}
+	return false
+}
+
+func (tc TemplateConfig) check() error {
+	for sink, st := range map[string]SinkTemplate{"redmine": tc.Redmine, "jira": tc.Jira} {
+		for _, text := range []string{st.Comment, st.Fallback} {
+			if _, err := template.New(sink).Parse(text); err != nil {
+				return fmt.Errorf("invalid %s comment template: %s", sink, err)
+			}
+		}
+		if st.MaxLength < 0 {
+			return fmt.Errorf("invalid %s maximal comment length %d", sink, st.MaxLength)
+		}
+	}
+	return nil
+}
+
+// sink returns the template of a tracker, with the limits of the tracker.
+func (tc TemplateConfig) sink(prefix string) SinkTemplate {
+	if prefix == "PIM-" {
+		return tc.Jira
+	}
+
+	st := tc.Redmine
+	if st.MaxLength == 0 || st.MaxLength > redmineCommentLength {
+		st.MaxLength = redmineCommentLength
+	}
+	return st
+}
+
+func commentData(te TimeEntry, prefix string, issues map[string]IssueInfo) CommentData {
+	data := CommentData{
+		TimeEntry: te,
+		Tags:      strings.Join(te.Tags, ", "),
+		Hours:     fmt.Sprintf("%.2f", te.Hours.Hours()),
+		Date:      te.Start.In(localTime()).Format("2006-01-02"),
+	}
+	if issueIDs := te.issuesWithPrefix(prefix); len(issueIDs) > 0 {
+		info := issues[issueIDs[0]]
+		data.Issue = issueIDs[0]
+		data.IssueSubject = info.Subject
+		data.IssueProject = info.Project
+	}
+	return data
+}
+
+func execute(text string, data CommentData) (string, error) {
+	tmpl, err := template.New("comment").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var comment strings.Builder
+	if err := tmpl.Execute(&comment, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(comment.String()), nil
+}
+
+// render builds the comment of the entry. It reports whether the comment had
+// to be truncated.
+func (st SinkTemplate) render(te TimeEntry, prefix string, issues map[string]IssueInfo) (string, bool, error) {
+	data := commentData(te, prefix, issues)
+
+	text := st.Comment
+	if text == "" {
+		text = "{{.Comment}}"
+	}
+	comment, err := execute(text, data)
+	if err != nil {
+		return "", false, err
+	}
+	if comment == "" && st.Fallback != "" {
+		comment, err = execute(st.Fallback, data)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	comment, truncated := truncate(comment, st.MaxLength)
+	return comment, truncated, nil
+}
+
+// truncate shortens the comment to max characters, ending with an ellipsis.
+func truncate(comment string, max int) (string, bool) {
+	runes := []rune(comment)
+	if max <= 0 || len(runes) <= max {
+		return comment, false
+	}
+	if max <= 3 {
+		return string(runes[:max]), true
+	}
+	return strings.TrimSpace(string(runes[:max-3])) + "...", true
+}
+
+// commentCheck reports entries whose comment is empty for one of their
+// trackers, or which can not be rendered.
+func commentCheck(tc TemplateConfig, issues map[string]IssueInfo) Check {
+	return func(entries []TimeEntry) map[string][]string {
+		problems := map[string][]string{}
+		for _, entry := range entries {
+			prefixes := []string{}
+			if entry.IsRedmine {
+				prefixes = append(prefixes, "#")
+			}
+			if entry.IsJira {
+				prefixes = append(prefixes, "PIM-")
+			}
+			if len(prefixes) == 0 {
+				prefixes = append(prefixes, "#")
+			}
+
+			for _, prefix := range prefixes {
+				comment, truncated, err := tc.sink(prefix).render(entry, prefix, issues)
+				switch {
+				case err != nil:
+					problems[entry.key()] = append(problems[entry.key()], fmt.Sprintf("Comment template failed: %s", err))
+				case comment == "":
+					problems[entry.key()] = append(problems[entry.key()], "Comment is empty")
+				case truncated:
+					problems[entry.key()] = append(problems[entry.key()], "Comment is truncated")
+				}
+				if err != nil || comment == "" {
+					break
+				}
+			}
+		}
+		return problems
+	}
+}
+
+// applyTo renders the comments of the entries for the tracker. Entries whose
+// template fails get an error, so they are not pushed.
+func (st SinkTemplate) applyTo(entries []TimeEntry, prefix string, issues map[string]IssueInfo) []TimeEntry {
+	result := []TimeEntry{}
+	for _, entry := range entries {
+		comment, truncated, err := st.render(entry, prefix, issues)
+		if err != nil {
+			entry.errors = append(entry.errors, fmt.Sprintf("Comment template failed: %s", err))
+		} else {
+			entry.Comment = comment
+		}
+		if truncated {
+			log.Printf("Truncated the comment of %s to %d characters", entry.ID, st.MaxLength)
+		}
+		result = append(result, entry)
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderComment(t *testing.T) {
+	entry := TimeEntry{
+		ID:       "1",
+		Comment:  "fixed the login",
+		Tags:     []string{"R_12", "backend"},
+		IssueIDs: []string{"#12"},
+		Start:    time.Date(2026, 10, 5, 8, 0, 0, 0, time.UTC),
+		Hours:    90 * time.Minute,
+	}
+	issues := map[string]IssueInfo{"#12": {ID: "#12", Subject: "Login"}}
+
+	tests := []struct {
+		name      string
+		template  SinkTemplate
+		entry     TimeEntry
+		comment   string
+		truncated bool
+	}{
+		{"default", SinkTemplate{}, entry, "fixed the login", false},
+		{"fields", SinkTemplate{Comment: "{{.Issue}} {{.IssueSubject}}: {{.Comment}} ({{.Hours}}h)"}, entry, "#12 Login: fixed the login (1.50h)", false},
+		{"fallback", SinkTemplate{Fallback: "Work on {{.IssueSubject}}"}, TimeEntry{IssueIDs: []string{"#12"}}, "Work on Login", false},
+		{"empty", SinkTemplate{}, TimeEntry{}, "", false},
+		{"truncated", SinkTemplate{MaxLength: 8}, entry, "fixed...", true},
+	}
+
+	for _, test := range tests {
+		comment, truncated, err := test.template.render(test.entry, "#", issues)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err)
+			continue
+		}
+		if comment != test.comment || truncated != test.truncated {
+			t.Errorf("%s: expected %q (%v), got %q (%v)", test.name, test.comment, test.truncated, comment, truncated)
+		}
+	}
+}
+
+func TestRedmineCommentLength(t *testing.T) {
+	tc := TemplateConfig{Redmine: SinkTemplate{MaxLength: 2000}}
+	if max := tc.sink("#").MaxLength; max != redmineCommentLength {
+		t.Errorf("expected the Redmine limit %d, got %d", redmineCommentLength, max)
+	}
+	if max := tc.sink("PIM-").MaxLength; max != 0 {
+		t.Errorf("expected no JIRA limit, got %d", max)
+	}
+
+	entry := TimeEntry{Comment: strings.Repeat("a", 1100)}
+	comment, truncated, err := tc.sink("#").render(entry, "#", nil)
+	if err != nil || !truncated || len([]rune(comment)) != redmineCommentLength {
+		t.Errorf("expected a comment of %d characters, got %d (%v, %v)", redmineCommentLength, len(comment), truncated, err)
+	}
+}
+
+func TestSinkTemplateUsesIssues(t *testing.T) {
+	tests := map[SinkTemplate]bool{
+		{}:                                      false,
+		{Comment: "{{.Issue}}: {{.Comment}}"}:   false,
+		{Comment: "{{.IssueSubject}}"}:          true,
+		{Fallback: "work on {{.IssueProject}}"}: true,
+	}
+	for st, expected := range tests {
+		if st.usesIssues() != expected {
+			t.Errorf("expected %v for %+v", expected, st)
+		}
+	}
+}
+
+func TestCommentCheck(t *testing.T) {
+	tc := TemplateConfig{Jira: SinkTemplate{Comment: "{{.Missing}}"}}
+	entries := []TimeEntry{
+		{ID: "1", Comment: "fixed the login", IsRedmine: true},
+		{ID: "2", IsRedmine: true},
+		{ID: "3", Comment: "review", IsJira: true},
+	}
+
+	problems := commentCheck(tc, nil)(entries)
+	if len(problems[entries[0].key()]) != 0 {
+		t.Errorf("expected no problems for 1, got %q", problems[entries[0].key()])
+	}
+	if len(problems[entries[1].key()]) != 1 || problems[entries[1].key()][0] != "Comment is empty" {
+		t.Errorf("expected an empty comment for 2, got %q", problems[entries[1].key()])
+	}
+	if len(problems[entries[2].key()]) != 1 || !strings.HasPrefix(problems[entries[2].key()][0], "Comment template failed") {
+		t.Errorf("expected a failed template for 3, got %q", problems[entries[2].key()])
+	}
+	if err := tc.check(); err != nil {
+		t.Errorf("expected the template to parse, got %s", err)
+	}
+}
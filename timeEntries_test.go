@@ -2,6 +2,7 @@ package main
 
 import (
 	"testing"
+	"time"
 )
 
 func TestEntryListFromJSON(t *testing.T) {
@@ -21,3 +22,93 @@ func TestEntryListFromJSON(t *testing.T) {
 	//	}
 	//}
 }
+
+func TestEntryListGroupBy(t *testing.T) {
+	el := EntryList{}
+
+	if err := el.fromJSONFile("testdata/entries.json"); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	key, err := groupKey("day", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	keys, groups := el.groupBy(key)
+	if len(keys) != 1 || keys[0] != "2024-02-03" {
+		t.Errorf("Expected one group 2024-02-03, got %v", keys)
+	}
+
+	if len(groups["2024-02-03"]) != 4 {
+		t.Errorf("Expected 4 entries in group, got %d", len(groups["2024-02-03"]))
+	}
+
+	if _, err := groupKey("month", nil); err == nil {
+		t.Errorf("Expected error for invalid group")
+	}
+
+	// 00:30 CEST is still the previous day in UTC
+	start := time.Date(2026, time.October, 6, 0, 30, 0, 0, localTime())
+	if day := key(TimeEntry{Start: start, End: start.Add(time.Hour)}); day != "2026-10-06" {
+		t.Errorf("Expected the local day 2026-10-06, got %s", day)
+	}
+}
+
+func TestTimeEntrySplitAtMidnight(t *testing.T) {
+	loc := localTime()
+	start := time.Date(2026, time.October, 5, 22, 30, 0, 0, loc)
+	end := time.Date(2026, time.October, 6, 1, 15, 0, 0, loc)
+	entry := TimeEntry{ID: "1", Start: start, End: end, Hours: end.Sub(start)}
+
+	portions := entry.splitAtMidnight(loc)
+	if len(portions) != 2 {
+		t.Fatalf("Expected 2 portions, got %d", len(portions))
+	}
+
+	if portions[0].Hours != 90*time.Minute || portions[1].Hours != 75*time.Minute {
+		t.Errorf("Expected 1:30 and 1:15, got %s and %s", formatDuration(portions[0].Hours), formatDuration(portions[1].Hours))
+	}
+
+	if portions[1].Start.In(loc).Format("2006-01-02 15:04") != "2026-10-06 00:00" {
+		t.Errorf("Expected second portion to start at midnight, got %s", portions[1].Start.In(loc))
+	}
+
+	if portions[0].ID != "1" || portions[1].ID != "1" {
+		t.Errorf("Expected portions to keep the ID")
+	}
+
+	if len(portions[0].splitAtMidnight(loc)) != 1 {
+		t.Errorf("Expected a portion not to be split again")
+	}
+}
+
+func TestParseAliases(t *testing.T) {
+	aliases := map[string]Alias{
+		"infra":      {Issue: "#48213", Activity: "9"},
+		"onboarding": {Issue: "PIM-1200"},
+	}
+
+	entry, err := parse(TimeWarriorEntry{
+		ID:    1,
+		Start: "20261005T080000Z",
+		End:   "20261005T100000Z",
+		Tags:  []string{"infra", "onboarding", "setting up the vpn"},
+	}, aliases)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	if !entry.IsRedmine || !entry.IsJira {
+		t.Errorf("Expected a Redmine and JIRA entry")
+	}
+	if len(entry.IssueIDs) != 2 || entry.IssueIDs[0] != "#48213" || entry.IssueIDs[1] != "PIM-1200" {
+		t.Errorf("Expected #48213 and PIM-1200, got %v", entry.IssueIDs)
+	}
+	if entry.ActivityID != "9" {
+		t.Errorf("Expected activity 9, got %q", entry.ActivityID)
+	}
+	if len(entry.Tags) != 0 {
+		t.Errorf("Expected the aliases to be removed from the tags, got %v", entry.Tags)
+	}
+}
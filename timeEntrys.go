@@ -3,9 +3,7 @@ package main
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -24,42 +22,160 @@ type TimeEntry struct {
 	errors     []string
 	IsRedmine  bool
 	IsJira     bool
+	// Rule is the name of the rule which assigned the issues.
+	Rule string
+	// Weights holds the weights of issues tagged like `R_100:70`.
+	Weights map[string]float64
+	// timewTags are the tags as stored in timewarrior.
+	timewTags []string
+	// Annotation is the annotation of the interval, which is part of the
+	// comment.
+	Annotation string
+	// Running marks the interval which is still tracked. Its end is the time
+	// it was read.
+	Running bool
 }
 
-func (te *TimeEntry) unmark(marker string) error {
-	timewCommand := fmt.Sprintf("timew @%s untag %s", te.ID, marker)
-	timewOutput, err := exec.Command("bash", "-c", timewCommand).Output()
-	if err != nil {
+func (te *TimeEntry) hasTag(tag string) bool {
+	for _, t := range te.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (te *TimeEntry) hasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		if te.hasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSynced reports whether the entry was pushed to every system it belongs to.
+func (te *TimeEntry) isSynced() bool {
+	if !te.IsRedmine && !te.IsJira {
+		return false
+	}
+	if te.IsRedmine && !te.hasTag("S2R") {
+		return false
+	}
+	if te.IsJira && !te.hasTag("S2J") {
+		return false
+	}
+	return true
+}
+
+// modifyStart moves the start of the interval in timewarrior.
+func (te *TimeEntry) modifyStart(start time.Time) error {
+	done := record(JournalChange{Action: "modify start", At: start, From: timeRef(te.Start), To: timeRef(start)})
+	if err := timew("modify", "start", "@"+te.ID, start.UTC().Format("20060102T150405Z")); err != nil {
 		return err
 	}
+	done()
 
-	log.Printf("%s", timewOutput)
+	te.Start = start
+	te.Hours = te.End.Sub(te.Start)
 	return nil
 }
 
-func (te *TimeEntry) mark(marker string) error {
-	timewCommand := fmt.Sprintf("timew @%s tag %s", te.ID, marker)
-	timewOutput, err := exec.Command("bash", "-c", timewCommand).Output()
-	if err != nil {
+// modifyEnd moves the end of the interval in timewarrior.
+func (te *TimeEntry) modifyEnd(end time.Time) error {
+	done := record(JournalChange{Action: "modify end", At: te.Start, From: timeRef(te.End), To: timeRef(end)})
+	if err := timew("modify", "end", "@"+te.ID, end.UTC().Format("20060102T150405Z")); err != nil {
 		return err
 	}
+	done()
 
-	log.Printf("%s", timewOutput)
+	te.End = end
+	te.Hours = te.End.Sub(te.Start)
+	return nil
+}
+
+// continueAt stops the running interval and tracks on from the same time in
+// a new interval with its tags, except the sync markers.
+func (te *TimeEntry) continueAt(at time.Time) error {
+	tags := []string{}
+	for _, tag := range te.timewTags {
+		if !strings.HasPrefix(tag, "S2R") && !strings.HasPrefix(tag, "S2J") {
+			tags = append(tags, tag)
+		}
+	}
+
+	done := record(JournalChange{Action: "continue", At: at, From: timeRef(te.Start)})
+	timestamp := at.UTC().Format("20060102T150405Z")
+	if err := timew("stop", timestamp); err != nil {
+		return err
+	}
+	if err := timew(append([]string{"start", timestamp}, tags...)...); err != nil {
+		return err
+	}
+	done()
+
+	te.End = at
+	te.Running = false
 	return nil
 }
 
 type EntryList struct {
 	Entries []TimeEntry
+	// Aliases are resolved to issues when parsing the tags.
+	Aliases map[string]Alias
+	// raw keeps intervals which cross midnight in one piece.
+	raw bool
+	// running includes the running interval up to now.
+	running bool
 }
 
+// fromTimeWarrior reads the entries of a range like `week` from the
+// timewarrior data files.
 func (el *EntryList) fromTimeWarrior(time_range string) error {
-	timewCommand := fmt.Sprintf("timew export :%s", time_range)
-	timewOutput, err := exec.Command("bash", "-c", timewCommand).Output()
+	from, to, err := timeRange(time_range, time.Now(), localTime(), weekStart())
 	if err != nil {
 		return err
 	}
 
-	return el.fromJSON(timewOutput)
+	return el.fromTimeWarriorInterval(from, to)
+}
+
+// fromTimeWarriorInterval reads the entries between from and to. When run as
+// extension the range of timewarrior is used instead.
+func (el *EntryList) fromTimeWarriorInterval(from, to time.Time) error {
+	if extension != nil {
+		// timewarrior filtered the intervals of the extension already
+		if err := el.fromIntervals(extension.Intervals); err != nil {
+			return err
+		}
+	} else if err := el.fromTimeWarriorData(from, to); err != nil {
+		return err
+	}
+
+	if !el.raw {
+		el.splitAtMidnight(localTime())
+	}
+
+	return nil
+}
+
+// fromOwnRange reads the entries of a range the command computes itself,
+// like the week of the timesheet. Timewarrior passes only the intervals of
+// its range to extensions, so this fails when run as extension.
+func (el *EntryList) fromOwnRange(command string, from, to time.Time) error {
+	if extension != nil {
+		return fmt.Errorf("%s needs its own time range and can not run as timewarrior extension", command)
+	}
+	return el.fromTimeWarriorInterval(from, to)
+}
+
+// localTime returns the location used to display and group entries.
+func localTime() *time.Location {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		return time.Local
+	}
+	return loc
 }
 
 func (el *EntryList) filterPending() {
@@ -84,72 +200,157 @@ func (el *EntryList) filterPending() {
 	el.Entries = filtered
 }
 
-func (el *EntryList) list() tablewriter.Table {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "Start", "End", "Hours", "IssueIDs", "Comment", "Tags", "Problems"})
-
-	sum := 0.0
-	sum4day := 0.0
-	currentDay := ""
+// groupBy partitions the entries by the given key. The keys are returned in
+// the order of their first appearance.
+func (el *EntryList) groupBy(key func(TimeEntry) string) ([]string, map[string][]TimeEntry) {
+	keys := []string{}
+	groups := map[string][]TimeEntry{}
 	for _, entry := range el.Entries {
-		if currentDay == "" {
-			currentDay = entry.Start.Format("2006-01-02")
+		k := key(entry)
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
 		}
+		groups[k] = append(groups[k], entry)
+	}
 
-		if currentDay != entry.Start.Format("2006-01-02") {
-			table.Append([]string{" ", " ", currentDay, "= " + fmt.Sprintf("%.2f", sum4day), " ", " ", " ", " "})
-			sum4day = 0.0
-			currentDay = entry.Start.Format("2006-01-02")
-		}
+	return keys, groups
+}
 
-		// checking for problems
-		if len(entry.Comment) == 0 {
-			entry.errors = append(entry.errors, "Comment is empty")
-		}
+// groupKey returns the key function for the `--group-by` values of `list`.
+func groupKey(groupBy string, issues map[string]IssueInfo) (func(TimeEntry) string, error) {
+	switch groupBy {
+	case "day":
+		return func(te TimeEntry) string {
+			return te.Start.In(localTime()).Format("2006-01-02")
+		}, nil
+	case "issue":
+		return func(te TimeEntry) string {
+			if len(te.IssueIDs) == 0 {
+				return "-"
+			}
+			return strings.Join(te.IssueIDs, ", ")
+		}, nil
+	case "project":
+		return func(te TimeEntry) string {
+			for _, issueID := range te.IssueIDs {
+				if info, ok := issues[issueID]; ok && info.Project != "" {
+					return info.Project
+				}
+			}
+			return "-"
+		}, nil
+	}
 
-		if entry.IsJira && !entry.IsRedmine {
-			entry.errors = append(entry.errors, "Jira entry without Redmine issue")
-		}
+	return nil, fmt.Errorf("invalid group %q, please use 'day', 'issue' or 'project'", groupBy)
+}
 
-		if entry.IsRedmine && entry.IsJira && len(entry.IssueIDs) < 2 {
-			entry.errors = append(entry.errors, "Redmine and Jira issue without both issue IDs")
+type listOptions struct {
+	GroupBy string
+	// Issues holds the resolved issue details. The subject, project and status
+	// columns are only rendered when it is set.
+	Issues map[string]IssueInfo
+}
+
+func (el *EntryList) list(opts listOptions) (*tablewriter.Table, error) {
+	if opts.GroupBy == "" {
+		opts.GroupBy = "day"
+	}
+	key, err := groupKey(opts.GroupBy, opts.Issues)
+	if err != nil {
+		return nil, err
+	}
+
+	details := opts.Issues != nil
+	header := []string{"ID", "Start", "End", "Hours", "IssueIDs"}
+	if details {
+		header = append(header, "Subject", "Project", "Status")
+	}
+	header = append(header, "Comment", "Tags", "Problems")
+
+	// row pads the given cells to the width of the header
+	row := func(cells ...string) []string {
+		for len(cells) < len(header) {
+			cells = append(cells, " ")
 		}
+		return cells
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+
+	loc := localTime()
 
-		if entry.IsRedmine && entry.ActivityID == "" {
-			entry.errors = append(entry.errors, "Redmine entry without activity ID")
+	sum := 0.0
+	keys, groups := el.groupBy(key)
+	for _, k := range keys {
+		sum4group := 0.0
+		for _, entry := range groups[k] {
+			sum4group += entry.Hours.Hours()
+			sum += entry.Hours.Hours()
+
+			issueIDs := []string{}
+			for _, issueID := range entry.IssueIDs {
+				if weight, ok := entry.Weights[issueID]; ok {
+					issueID = fmt.Sprintf("%s (%g)", issueID, weight)
+				}
+				issueIDs = append(issueIDs, issueID)
+			}
+			if entry.Rule != "" {
+				issueIDs = append(issueIDs, "via "+entry.Rule)
+			}
+
+			end := entry.End.In(loc).Format("2006-01-02 15:04:05")
+			if entry.Running {
+				end = "running"
+			}
+
+			cells := []string{
+				entry.ID,
+				entry.Start.In(loc).Format("2006-01-02 15:04:05"),
+				end,
+				fmt.Sprintf(
+					"%.2f",
+					entry.Hours.Hours(),
+				),
+				strings.Join(
+					issueIDs,
+					"\n",
+				),
+			}
+			if details {
+				subjects, projects, states := []string{}, []string{}, []string{}
+				for _, issueID := range entry.IssueIDs {
+					info := opts.Issues[issueID]
+					subjects = append(subjects, info.Subject)
+					projects = append(projects, info.Project)
+					states = append(states, info.Status)
+				}
+				cells = append(cells,
+					strings.Join(subjects, "\n"),
+					strings.Join(projects, "\n"),
+					strings.Join(states, "\n"),
+				)
+			}
+			cells = append(cells,
+				entry.Comment,
+				strings.Join(
+					entry.Tags,
+					"\n",
+				),
+				strings.Join(
+					entry.errors,
+					"\n",
+				),
+			)
+			table.Append(cells)
 		}
 
-		loc, _ := time.LoadLocation("Europe/Berlin")
-
-		sum4day += entry.Hours.Hours()
-		sum += entry.Hours.Hours()
-		table.Append([]string{
-			entry.ID,
-			entry.Start.In(loc).Format("2006-01-02 15:04:05"),
-			entry.End.In(loc).Format("2006-01-02 15:04:05"),
-			fmt.Sprintf(
-				"%.2f",
-				entry.Hours.Hours(),
-			),
-			strings.Join(
-				entry.IssueIDs,
-				"\n",
-			),
-			entry.Comment,
-			strings.Join(
-				entry.Tags,
-				"\n",
-			),
-			strings.Join(
-				entry.errors,
-				"\n",
-			),
-		})
-	}
-
-	table.SetFooter([]string{" ", " ", "Total", "= " + fmt.Sprintf("%.2f", sum), " ", " ", " ", " "})
-
-	return *table
+		table.Append(row(" ", " ", k, "= "+fmt.Sprintf("%.2f", sum4group)))
+	}
+
+	table.SetFooter(row(" ", " ", "Total", "= "+fmt.Sprintf("%.2f", sum)))
+
+	return table, nil
 }
 
 func (el *EntryList) fromJSONFile(filename string) error {
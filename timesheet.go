@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// parseISOWeek returns the monday of an ISO week given as `2026-W41`.
+func parseISOWeek(week string, loc *time.Location) (time.Time, error) {
+	var year, w int
+	if _, err := fmt.Sscanf(week, "%d-W%d", &year, &w); err != nil {
+		return time.Time{}, fmt.Errorf("invalid week %q, please use the format 2026-W41", week)
+	}
+	if w < 1 || w > 53 {
+		return time.Time{}, fmt.Errorf("invalid week %q, week must be between 1 and 53", week)
+	}
+
+	// the 4th of january is always in the first ISO week
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	offset := (int(jan4.Weekday()) + 6) % 7
+	monday := jan4.AddDate(0, 0, -offset+(w-1)*7)
+
+	if y, wk := monday.ISOWeek(); y != year || wk != w {
+		return time.Time{}, fmt.Errorf("invalid week %q, %d has no week %d", week, year, w)
+	}
+
+	return monday, nil
+}
+
+type TimesheetRow struct {
+	Issue string
+	Days  [7]float64
+	Total float64
+}
+
+type Timesheet struct {
+	Name   string
+	Monday time.Time
+	Rows   []TimesheetRow
+	Days   [7]float64
+	Total  float64
+}
+
+func (el *EntryList) timesheet(monday time.Time) *Timesheet {
+	ts := &Timesheet{Monday: monday}
+	rows := map[string]*TimesheetRow{}
+	loc := monday.Location()
+	for _, entry := range el.Entries {
+		day := -1
+		date := entry.Start.In(loc).Format("2006-01-02")
+		for i := 0; i < 7; i++ {
+			if monday.AddDate(0, 0, i).Format("2006-01-02") == date {
+				day = i
+			}
+		}
+		if day < 0 {
+			continue
+		}
+
+		issue := "-"
+		if len(entry.IssueIDs) > 0 {
+			issue = strings.Join(entry.IssueIDs, ", ")
+		}
+
+		row, ok := rows[issue]
+		if !ok {
+			row = &TimesheetRow{Issue: issue}
+			rows[issue] = row
+		}
+
+		hours := entry.Hours.Hours()
+		row.Days[day] += hours
+		row.Total += hours
+		ts.Days[day] += hours
+		ts.Total += hours
+	}
+
+	for _, row := range rows {
+		ts.Rows = append(ts.Rows, *row)
+	}
+	sort.Slice(ts.Rows, func(i, j int) bool {
+		return ts.Rows[i].Issue < ts.Rows[j].Issue
+	})
+
+	return ts
+}
+
+func (ts *Timesheet) Week() string {
+	year, week := ts.Monday.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func (ts *Timesheet) Header() []string {
+	header := []string{"Issue"}
+	for i := 0; i < 7; i++ {
+		header = append(header, ts.Monday.AddDate(0, 0, i).Format("Mon 02.01."))
+	}
+	return append(header, "Total")
+}
+
+func hoursCell(hours float64) string {
+	if hours == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", hours)
+}
+
+func (ts *Timesheet) Records() [][]string {
+	records := [][]string{}
+	for _, row := range ts.Rows {
+		record := []string{row.Issue}
+		for _, hours := range row.Days {
+			record = append(record, hoursCell(hours))
+		}
+		records = append(records, append(record, fmt.Sprintf("%.2f", row.Total)))
+	}
+	return records
+}
+
+func (ts *Timesheet) Totals() []string {
+	totals := []string{"Total"}
+	for _, hours := range ts.Days {
+		totals = append(totals, fmt.Sprintf("%.2f", hours))
+	}
+	return append(totals, fmt.Sprintf("%.2f", ts.Total))
+}
+
+var timesheetTemplate = template.Must(template.New("timesheet").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Timesheet {{.Week}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #999; padding: 0.3em 0.6em; }
+td.hours, th.hours { text-align: right; }
+tfoot td { font-weight: bold; }
+.signatures { display: flex; justify-content: space-between; margin-top: 4em; }
+.signature { border-top: 1px solid #000; width: 40%; padding-top: 0.3em; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Timesheet {{.Week}}</h1>
+{{if .Name}}<p>{{.Name}}</p>{{end}}
+<table>
+<thead>
+<tr>{{range $i, $h := .Header}}<th{{if $i}} class="hours"{{end}}>{{$h}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{range .Records}}<tr>{{range $i, $c := .}}<td{{if $i}} class="hours"{{end}}>{{$c}}</td>{{end}}</tr>
+{{end}}</tbody>
+<tfoot>
+<tr>{{range $i, $c := .Totals}}<td{{if $i}} class="hours"{{end}}>{{$c}}</td>{{end}}</tr>
+</tfoot>
+</table>
+<div class="signatures">
+<div class="signature">Date, signature employee</div>
+<div class="signature">Date, signature supervisor</div>
+</div>
+</body>
+</html>
+`))
+
+func (ts *Timesheet) write(w io.Writer, format string) error {
+	switch format {
+	case "table":
+		table := tablewriter.NewWriter(w)
+		table.SetHeader(ts.Header())
+		table.AppendBulk(ts.Records())
+		table.SetFooter(ts.Totals())
+		table.Render()
+		return nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(ts.Header()); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(ts.Records()); err != nil {
+			return err
+		}
+		if err := cw.Write(ts.Totals()); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	case "html":
+		return timesheetTemplate.Execute(w, ts)
+	}
+
+	return fmt.Errorf("invalid format %q, please use 'table', 'csv' or 'html'", format)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseISOWeek(t *testing.T) {
+	tests := map[string]string{
+		"2026-W41": "2026-10-05",
+		"2026-W01": "2025-12-29",
+		"2020-W53": "2020-12-28",
+	}
+
+	for week, expected := range tests {
+		monday, err := parseISOWeek(week, time.UTC)
+		if err != nil {
+			t.Errorf("Expected no error for %s, got %s", week, err)
+			continue
+		}
+		if monday.Format("2006-01-02") != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, week, monday.Format("2006-01-02"))
+		}
+	}
+
+	for _, week := range []string{"2021-W53", "2026-41", "2026-W0"} {
+		if _, err := parseISOWeek(week, time.UTC); err == nil {
+			t.Errorf("Expected error for %s", week)
+		}
+	}
+}
+
+func TestEntryListTimesheet(t *testing.T) {
+	monday, err := parseISOWeek("2026-W41", localTime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	el := EntryList{Entries: []TimeEntry{
+		complianceEntry("5", "2026-10-05 08:00", "2026-10-05 10:00"),
+		complianceEntry("4", "2026-10-05 10:00", "2026-10-05 11:30"),
+		complianceEntry("3", "2026-10-07 09:00", "2026-10-07 12:00"),
+		complianceEntry("2", "2026-10-11 10:00", "2026-10-11 10:30"),
+		// the week before is left out
+		complianceEntry("1", "2026-10-04 10:00", "2026-10-04 12:00"),
+	}}
+	el.Entries[0].IssueIDs = []string{"#100"}
+	el.Entries[1].IssueIDs = []string{"#200", "PIM-5"}
+	el.Entries[2].IssueIDs = []string{"#100"}
+
+	ts := el.timesheet(monday)
+	if ts.Week() != "2026-W41" || ts.Total != 7 {
+		t.Errorf("Expected 7 hours in 2026-W41, got %.2f in %s", ts.Total, ts.Week())
+	}
+
+	expected := [][]string{
+		{"#100", "2.00", "", "3.00", "", "", "", "", "5.00"},
+		{"#200, PIM-5", "1.50", "", "", "", "", "", "", "1.50"},
+		{"-", "", "", "", "", "", "", "0.50", "0.50"},
+	}
+	records := ts.Records()
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %d rows, got %v", len(expected), records)
+	}
+	for i := range expected {
+		if strings.Join(records[i], "|") != strings.Join(expected[i], "|") {
+			t.Errorf("Expected row %v, got %v", expected[i], records[i])
+		}
+	}
+	totals := []string{"Total", "3.50", "0.00", "3.00", "0.00", "0.00", "0.00", "0.50", "7.00"}
+	if strings.Join(ts.Totals(), "|") != strings.Join(totals, "|") {
+		t.Errorf("Expected totals %v, got %v", totals, ts.Totals())
+	}
+	if header := ts.Header(); header[1] != "Mon 05.10." || header[7] != "Sun 11.10." || header[8] != "Total" {
+		t.Errorf("Unexpected header %v", header)
+	}
+
+	var out bytes.Buffer
+	if err := ts.write(&out, "csv"); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || lines[2] != `"#200, PIM-5",1.50,,,,,,,1.50` || lines[4] != "Total,3.50,0.00,3.00,0.00,0.00,0.00,0.50,7.00" {
+		t.Errorf("Unexpected CSV %q", out.String())
+	}
+
+	out.Reset()
+	ts.Name = "Jane <Doe>"
+	if err := ts.write(&out, "html"); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	html := out.String()
+	for _, part := range []string{"<title>Timesheet 2026-W41</title>", "<p>Jane &lt;Doe&gt;</p>", `<td class="hours">5.00</td>`, `<td class="hours">7.00</td>`} {
+		if !strings.Contains(html, part) {
+			t.Errorf("Expected %q in the HTML", part)
+		}
+	}
+
+	if err := ts.write(&out, "pdf"); err == nil {
+		t.Errorf("Expected an error for an invalid format")
+	}
+}
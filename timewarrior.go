@@ -9,86 +9,87 @@ import (
 )
 
 type TimeWarriorEntry struct {
-	ID    int64
-	Start string
-	End   string
-	Tags  []string
+	ID         int64
+	Start      string
+	End        string
+	Tags       []string
+	Annotation string
 }
 
-func parse(entry TimeWarriorEntry) (*TimeEntry, error) {
+func parse(entry TimeWarriorEntry, aliases map[string]Alias) (*TimeEntry, error) {
 	startTime, err := time.Parse("20060102T150405Z", entry.Start)
 	if err != nil {
 		return nil, err
 	}
 
-	if entry.End == "" {
-		return nil, nil
-	}
-
-	endTime, err := time.Parse("20060102T150405Z", entry.End)
-	if err != nil {
-		return nil, err
-	}
-
-	comment := ""
-	tags := []string{}
-
-	for _, t := range entry.Tags {
-		if strings.Contains(t, " ") {
-			comment = t
-		} else {
-			tags = append(tags, t)
+	// the running interval has no end yet, so it lasts until now
+	running := entry.End == ""
+	endTime := time.Now().UTC().Truncate(time.Second)
+	if !running {
+		endTime, err = time.Parse("20060102T150405Z", entry.End)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	if comment == "" {
-		comment = ""
-	} else {
-		if strings.Contains(comment, ",") {
-			commentParts := strings.Split(comment, ",")
-			com := []string{}
-			for _, part := range commentParts {
-				part = strings.TrimSpace(part)
-
-				if strings.Contains(part, " ") {
-					// this is a sentence with spaces, so it's not a tag
-					com = append(com, part)
-				} else {
-					// this is a tag
-					tags = append(tags, part)
-				}
-			}
-
-			comment = strings.Join(com, ", ")
-		}
-	}
+	comment, tags := comments.parseComment(entry.Tags, entry.Annotation)
 
 	isJira := false
 	isRedmine := false
-	rexp := regexp.MustCompile(`[RJA]_\w+`)
+	rexp := regexp.MustCompile(`([RJA]_)((?:[A-Z][A-Z0-9]*-)?\w+)(?::(\d+(?:\.\d+)?))?`)
 	activityID := ""
 	issueIDs := []string{}
+	weights := map[string]float64{}
 	tmp := []string{}
 	for _, t := range tags {
-		matches := rexp.FindAllString(t, -1)
+		if alias, ok := aliases[t]; ok {
+			switch {
+			case hasIssuePrefix(alias.Issue, "PIM-"):
+				isJira = true
+			case strings.HasPrefix(alias.Issue, "#"):
+				isRedmine = true
+			}
+			issueIDs = append(issueIDs, alias.Issue)
+			if activityID == "" {
+				activityID = alias.Activity
+			}
+			continue
+		}
+
+		matches := rexp.FindAllStringSubmatch(t, -1)
 		if len(matches) == 0 {
 			tmp = append(tmp, t)
 			continue
 		}
 
 		for _, match := range matches {
-			prefix := match[:2]
-			issueID := match[2:]
+			prefix := match[1]
+			issueID := match[2]
 			switch prefix {
 			case "J_":
 				isJira = true
-				issueIDs = append(issueIDs, "PIM-"+issueID)
+				// `J_45` is PIM-45, other projects are tagged with the key
+				if !strings.Contains(issueID, "-") {
+					issueID = "PIM-" + issueID
+				}
+				issueIDs = append(issueIDs, issueID)
 			case "R_":
 				isRedmine = true
-				issueIDs = append(issueIDs, "#"+issueID)
+				issueID = "#" + issueID
+				issueIDs = append(issueIDs, issueID)
 			case "A_":
 				activityID = issueID
 				tmp = append(tmp, t)
+				continue
+			}
+
+			// a weight like `R_100:70` distributes the hours across the issues
+			if match[3] != "" {
+				weight, err := strconv.ParseFloat(match[3], 64)
+				if err != nil {
+					return nil, err
+				}
+				weights[issueID] = weight
 			}
 		}
 	}
@@ -108,6 +109,10 @@ func parse(entry TimeWarriorEntry) (*TimeEntry, error) {
 		IsJira:     isJira,
 		IsRedmine:  isRedmine,
 		ActivityID: activityID,
+		Weights:    weights,
+		timewTags:  entry.Tags,
+		Annotation: entry.Annotation,
+		Running:    running,
 	}, nil
 }
 
@@ -117,12 +122,16 @@ func (el *EntryList) fromJSON(data []byte) error {
 		return err
 	}
 
+	return el.fromIntervals(list)
+}
+
+func (el *EntryList) fromIntervals(list []TimeWarriorEntry) error {
 	for _, entry := range list {
-		timeEntry, err := parse(entry)
+		timeEntry, err := parse(entry, el.Aliases)
 		if err != nil {
 			return err
 		}
-		if timeEntry == nil {
+		if timeEntry.Running && !el.running {
 			continue
 		}
 
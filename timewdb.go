@@ -0,0 +1,374 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// timewDataDir returns the data directory of timewarrior. Like timewarrior
+// it honours TIMEWARRIORDB and falls back to the XDG location when
+// `~/.timewarrior` does not exist.
+func timewDataDir() (string, error) {
+	if db := os.Getenv("TIMEWARRIORDB"); db != "" {
+		return filepath.Join(db, "data"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	legacy := filepath.Join(home, ".timewarrior")
+	if _, err := os.Stat(legacy); err == nil {
+		return filepath.Join(legacy, "data"), nil
+	}
+
+	return filepath.Join(xdg.DataHome, "timewarrior", "data"), nil
+}
+
+// timewConfigFile returns the configuration file of timewarrior, found like
+// its data directory.
+func timewConfigFile() (string, error) {
+	if db := os.Getenv("TIMEWARRIORDB"); db != "" {
+		return filepath.Join(db, "timewarrior.cfg"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	legacy := filepath.Join(home, ".timewarrior")
+	if _, err := os.Stat(legacy); err == nil {
+		return filepath.Join(legacy, "timewarrior.cfg"), nil
+	}
+
+	return filepath.Join(xdg.ConfigHome, "timewarrior", "timewarrior.cfg"), nil
+}
+
+// parseTimewSetting returns the value of a `name = value` line of the
+// configuration. Later lines win, like in timewarrior.
+func parseTimewSetting(config, name string) string {
+	value := ""
+	for _, line := range strings.Split(config, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		key, setting, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == name {
+			value = strings.TrimSpace(setting)
+		}
+	}
+	return value
+}
+
+// timewSetting returns a setting of timewarrior, which passes its settings
+// to extensions. A missing configuration reads as empty.
+func timewSetting(name string) string {
+	if extension != nil {
+		return extension.Config[name]
+	}
+
+	path, err := timewConfigFile()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return parseTimewSetting(string(data), name)
+}
+
+// weekStart returns the first day of the week set by timewarrior's
+// `weekstart`, Monday by default.
+func weekStart() time.Weekday {
+	setting := strings.ToLower(timewSetting("weekstart"))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.ToLower(day.String()) == setting {
+			return day
+		}
+	}
+	return time.Monday
+}
+
+// dataToken is a word of a data file line. Quoted tokens are never
+// separators, even if they read `#`.
+type dataToken struct {
+	Text   string
+	Quoted bool
+}
+
+// splitDataLine splits the line at unquoted spaces. Quotes are removed and
+// escaped characters are unescaped.
+func splitDataLine(line string) ([]dataToken, error) {
+	tokens := []dataToken{}
+	var token strings.Builder
+	inToken, quoted, wasQuoted, escaped := false, false, false, false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			token.WriteRune(r)
+			escaped = false
+		case r == '\\' && quoted:
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			inToken, wasQuoted = true, true
+		case r == ' ' && !quoted:
+			if inToken {
+				tokens = append(tokens, dataToken{Text: token.String(), Quoted: wasQuoted})
+				token.Reset()
+				inToken, wasQuoted = false, false
+			}
+		default:
+			token.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inToken {
+		tokens = append(tokens, dataToken{Text: token.String(), Quoted: wasQuoted})
+	}
+
+	return tokens, nil
+}
+
+// parseDataLine parses an interval of a timewarrior data file, e.g.
+// `inc 20261005T080000Z - 20261005T100000Z # R_123 "fixed the login"`.
+// Open intervals have no end. The tags follow the first `#`, the annotation
+// the second one.
+func parseDataLine(line string) (TimeWarriorEntry, error) {
+	tokens, err := splitDataLine(line)
+	if err != nil {
+		return TimeWarriorEntry{}, err
+	}
+	if len(tokens) < 2 || tokens[0].Text != "inc" {
+		return TimeWarriorEntry{}, fmt.Errorf("not an interval")
+	}
+
+	entry := TimeWarriorEntry{Start: tokens[1].Text, Tags: []string{}}
+	rest := tokens[2:]
+	if len(rest) >= 2 && rest[0].Text == "-" && !rest[0].Quoted {
+		entry.End = rest[1].Text
+		rest = rest[2:]
+	}
+
+	if len(rest) == 0 {
+		return entry, nil
+	}
+	if rest[0].Text != "#" || rest[0].Quoted {
+		return TimeWarriorEntry{}, fmt.Errorf("unexpected %q", rest[0].Text)
+	}
+
+	annotation := []string{}
+	separators := 1
+	for _, token := range rest[1:] {
+		switch {
+		case token.Text == "#" && !token.Quoted:
+			separators++
+		case separators == 1:
+			entry.Tags = append(entry.Tags, token.Text)
+		default:
+			annotation = append(annotation, token.Text)
+		}
+	}
+	entry.Annotation = strings.Join(annotation, " ")
+
+	return entry, nil
+}
+
+// readTimewData reads all intervals of the data files. The IDs are assigned
+// like timewarrior does, counting from the newest interval as `@1`.
+func readTimewData(dir string) ([]TimeWarriorEntry, error) {
+	// the intervals are stored per month, next to files like tags.data
+	files, err := filepath.Glob(filepath.Join(dir, "[0-9][0-9][0-9][0-9]-[0-9][0-9].data"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("timewarrior data not found: %s", err)
+		}
+	}
+
+	entries := []TimeWarriorEntry{}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		number := 0
+		for scanner.Scan() {
+			number++
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "inc ") {
+				continue
+			}
+
+			entry, err := parseDataLine(line)
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("%s:%d: %s", file, number, err)
+			}
+			entries = append(entries, entry)
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// the timestamps sort like the times they represent
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start < entries[j].Start
+	})
+	for i := range entries {
+		entries[i].ID = int64(len(entries) - i)
+	}
+
+	return entries, nil
+}
+
+// timeRange returns the bounds of the named range like timewarrior's `:week`,
+// which starts on weekStart.
+func timeRange(name string, now time.Time, loc *time.Location, weekStart time.Weekday) (time.Time, time.Time, error) {
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	switch name {
+	case "all":
+		return time.Time{}, time.Date(9999, 1, 1, 0, 0, 0, 0, loc), nil
+	case "day":
+		return today, today.AddDate(0, 0, 1), nil
+	case "week":
+		first := today.AddDate(0, 0, -((int(today.Weekday()) - int(weekStart) + 7) % 7))
+		return first, first.AddDate(0, 0, 7), nil
+	case "month":
+		first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+		return first, first.AddDate(0, 1, 0), nil
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid time range %q", name)
+}
+
+// intervalsBetween returns the intervals overlapping from and to. Open
+// intervals last until now.
+func intervalsBetween(entries []TimeWarriorEntry, from, to time.Time) ([]TimeWarriorEntry, error) {
+	result := []TimeWarriorEntry{}
+	for _, entry := range entries {
+		start, err := time.Parse("20060102T150405Z", entry.Start)
+		if err != nil {
+			return nil, err
+		}
+		end := time.Now()
+		if entry.End != "" {
+			end, err = time.Parse("20060102T150405Z", entry.End)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if start.Before(to) && end.After(from) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// fromTimeWarriorData reads the entries between from and to from the data
+// files.
+func (el *EntryList) fromTimeWarriorData(from, to time.Time) error {
+	dir, err := timewDataDir()
+	if err != nil {
+		return err
+	}
+
+	all, err := readTimewData(dir)
+	if err != nil {
+		return err
+	}
+
+	intervals, err := intervalsBetween(all, from, to)
+	if err != nil {
+		return err
+	}
+
+	return el.fromIntervals(intervals)
+}
+
+// changeBatch limits the intervals per timewarrior call, keeping the
+// arguments well below the limits of the OS.
+const changeBatch = 500
+
+// changeTags adds or removes the tags of many intervals with few `timew tag`
+// or `timew untag` calls. When a batch fails, its intervals are changed one
+// by one to find the failing ones.
+func changeTags(action string, entries []TimeEntry, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	failed := []string{}
+	for len(entries) > 0 {
+		batch := entries
+		if len(batch) > changeBatch {
+			batch = batch[:changeBatch]
+		}
+		entries = entries[len(batch):]
+
+		args := []string{action}
+		done := []func(){}
+		for _, entry := range batch {
+			args = append(args, "@"+entry.ID)
+			done = append(done, recordTags(action, entry.Start, entry.timewTags, tags))
+		}
+		if err := timew(append(args, tags...)...); err == nil {
+			for _, changed := range done {
+				changed()
+			}
+			continue
+		}
+
+		for i, entry := range batch {
+			if err := timew(append([]string{action, "@" + entry.ID}, tags...)...); err != nil {
+				log.Printf("Could not %s @%s: %s", action, entry.ID, err)
+				failed = append(failed, "@"+entry.ID)
+				continue
+			}
+			done[i]()
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("could not %s %s", action, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// changeTagGroups changes each tag on its group of intervals, with one
+// batch per tag.
+func changeTagGroups(action string, groups map[string][]TimeEntry) error {
+	tags := []string{}
+	for tag := range groups {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		if err := changeTags(action, groups[tag], []string{tag}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
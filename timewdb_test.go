@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseDataLine(t *testing.T) {
+	tests := []struct {
+		line       string
+		end        string
+		tags       []string
+		annotation string
+	}{
+		{`inc 20261005T080000Z - 20261005T100000Z # R_123 "fixed the login"`, "20261005T100000Z", []string{"R_123", "fixed the login"}, ""},
+		{`inc 20261005T080000Z - 20261005T100000Z # A_9 "said \"hello\"" "#" # "the annotation"`, "20261005T100000Z", []string{"A_9", `said "hello"`, "#"}, "the annotation"},
+		{`inc 20261005T080000Z - 20261005T100000Z # # "only annotated"`, "20261005T100000Z", []string{}, "only annotated"},
+		{`inc 20261005T080000Z # R_1`, "", []string{"R_1"}, ""},
+		{`inc 20261005T080000Z - 20261005T100000Z`, "20261005T100000Z", []string{}, ""},
+	}
+
+	for _, test := range tests {
+		entry, err := parseDataLine(test.line)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %s", test.line, err)
+			continue
+		}
+		if entry.Start != "20261005T080000Z" || entry.End != test.end {
+			t.Errorf("%s: expected %s - %s, got %s - %s", test.line, "20261005T080000Z", test.end, entry.Start, entry.End)
+		}
+		if len(entry.Tags) != len(test.tags) {
+			t.Errorf("%s: expected tags %q, got %q", test.line, test.tags, entry.Tags)
+			continue
+		}
+		for i := range test.tags {
+			if entry.Tags[i] != test.tags[i] {
+				t.Errorf("%s: expected tags %q, got %q", test.line, test.tags, entry.Tags)
+				break
+			}
+		}
+		if entry.Annotation != test.annotation {
+			t.Errorf("%s: expected annotation %q, got %q", test.line, test.annotation, entry.Annotation)
+		}
+	}
+
+	if _, err := parseDataLine(`inc 20261005T080000Z # "unterminated`); err == nil {
+		t.Errorf("Expected an error for an unterminated quote")
+	}
+}
+
+func TestReadTimewData(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"2026-09.data": "inc 20260930T080000Z - 20260930T100000Z # R_1\n",
+		"2026-10.data": "inc 20261001T080000Z - 20261001T100000Z # R_2\ninc 20261002T080000Z # R_3\n",
+		"tags.data":    "{}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := readTimewData(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 intervals, got %d", len(entries))
+	}
+	if entries[0].ID != 3 || entries[0].Tags[0] != "R_1" || entries[2].ID != 1 || entries[2].Tags[0] != "R_3" {
+		t.Errorf("Expected the newest interval to be @1, got %+v", entries)
+	}
+
+	from := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	selected, err := intervalsBetween(entries, from, from.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(selected) != 1 || selected[0].ID != 2 {
+		t.Errorf("Expected only @2 to overlap, got %+v", selected)
+	}
+}
+
+func TestTimeRange(t *testing.T) {
+	loc := localTime()
+	now := time.Date(2026, 10, 8, 15, 0, 0, 0, loc)
+
+	tests := map[string][2]string{
+		"day":   {"2026-10-08", "2026-10-09"},
+		"week":  {"2026-10-05", "2026-10-12"},
+		"month": {"2026-10-01", "2026-11-01"},
+	}
+	for name, expected := range tests {
+		from, to, err := timeRange(name, now, loc, time.Monday)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %s", name, err)
+			continue
+		}
+		if from.Format("2006-01-02") != expected[0] || to.Format("2006-01-02") != expected[1] {
+			t.Errorf("%s: expected %s - %s, got %s - %s", name, expected[0], expected[1], from, to)
+		}
+	}
+
+	if _, _, err := timeRange("year", now, loc, time.Monday); err == nil {
+		t.Errorf("Expected an error for an unknown range")
+	}
+
+	// with `weekstart = sunday` the week of Thursday starts on the Sunday before
+	from, to, err := timeRange("week", now, loc, time.Sunday)
+	if err != nil || from.Format("2006-01-02") != "2026-10-04" || to.Format("2006-01-02") != "2026-10-11" {
+		t.Errorf("Expected the week from Sunday 2026-10-04, got %s - %s (%v)", from, to, err)
+	}
+}
+
+func TestParseTimewSetting(t *testing.T) {
+	config := "# week\nweekstart = monday\nimport /usr/share/timewarrior/themes/dark.theme\nweekstart = Sunday # US\n"
+	if setting := parseTimewSetting(config, "weekstart"); setting != "Sunday" {
+		t.Errorf("Expected Sunday, got %q", setting)
+	}
+	if setting := parseTimewSetting(config, "verbose"); setting != "" {
+		t.Errorf("Expected no setting, got %q", setting)
+	}
+}
+
+func TestChangeTagsReportsFailingIntervals(t *testing.T) {
+	// a fake timew which fails for interval @2
+	dir := t.TempDir()
+	script := "#!/bin/sh\nfor arg in \"$@\"; do [ \"$arg\" = \"@2\" ] && exit 1; done\nexit 0\n"
+	if err := os.WriteFile(filepath.Join(dir, "timew"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	journal = &Journal{path: filepath.Join(t.TempDir(), "journal.json")}
+	defer func() { journal = nil }()
+	journal.start("tag --tag infra")
+
+	entries := []TimeEntry{{ID: "3"}, {ID: "2"}, {ID: "1"}}
+	err := changeTags("tag", entries, []string{"infra"})
+	if err == nil || err.Error() != "could not tag @2" {
+		t.Errorf("Expected @2 to be reported, got %v", err)
+	}
+	// every interval is journaled before the batch, only @2 stays pending
+	if len(journal.Operations) != 1 || len(journal.Operations[0].Changes) != 3 {
+		t.Fatalf("Expected the 3 intervals to be journaled, got %+v", journal.Operations)
+	}
+	for i, change := range journal.Operations[0].Changes {
+		if change.Pending != (i == 1) {
+			t.Errorf("Expected only @2 to be pending, got %+v", journal.Operations[0].Changes)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/urfave/cli"
+)
+
+// timew runs timewarrior with the given arguments. The arguments are not
+// passed through a shell, so comments may contain any character.
+func timew(args ...string) error {
+	cmd := exec.Command("timew", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// timeTrackingEnabled checks whether the time tracking module of a project
+// is enabled.
+func (rl RedmineLogger) timeTrackingEnabled(projectID string) (bool, error) {
+	api, err := rl.getApi()
+	if err != nil {
+		return false, err
+	}
+
+	project, code, err := api.ProjectSingleGet(
+		projectID,
+		redmine.ProjectSingleGetRequest{
+			Includes: []redmine.ProjectInclude{redmine.ProjectIncludeEnabledModules},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	if code != 200 {
+		return false, fmt.Errorf("error getting project %s: %d", projectID, code)
+	}
+
+	if project.EnabledModules == nil {
+		return false, nil
+	}
+	for _, module := range *project.EnabledModules {
+		if module.Name == "time_tracking" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// issueTag returns the timewarrior tag for an issue, e.g. `R_123` for `#123`.
+func issueTag(issueID string) string {
+	if strings.HasPrefix(issueID, "PIM-") {
+		return "J_" + strings.TrimPrefix(issueID, "PIM-")
+	}
+	if hasIssuePrefix(issueID, "PIM-") {
+		return "J_" + issueID
+	}
+	return "R_" + strings.TrimPrefix(issueID, "#")
+}
+
+// trackingTags validates the issues and builds the tags for `timew start`.
+// The arguments are issues or aliases, optionally followed by the comment,
+// which has several words or starts with `c:`. A single word is taken for a
+// mistyped issue instead.
+func trackingTags(ctx *cli.Context, aliases map[string]Alias) ([]string, error) {
+	args := []string(ctx.Args())
+	if len(args) == 0 {
+		return nil, fmt.Errorf("please give at least one issue")
+	}
+
+	resolver, err := newIssueResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	activityID := ctx.String("activity")
+	comment := ""
+	for i, arg := range args {
+		if i == len(args)-1 && i > 0 && (strings.Contains(arg, " ") || strings.HasPrefix(arg, commentPrefix)) {
+			comment = strings.TrimPrefix(arg, commentPrefix)
+			break
+		}
+
+		issueID, err := normalizeIssue(arg)
+		if alias, ok := aliases[arg]; ok {
+			issueID, err = alias.Issue, nil
+			if activityID == "" {
+				activityID = alias.Activity
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := validateIssue(resolver, issueID, activityID)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(issueID, "#") && !resolver.Offline && resolver.Redmine != nil {
+			enabled, err := resolver.Redmine.timeTrackingEnabled(info.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			if !enabled {
+				return nil, fmt.Errorf("time tracking is not enabled in project %s", info.Project)
+			}
+		}
+
+		log.Printf("%s: %s (%s)", issueID, info.Subject, info.Project)
+		tags = append(tags, issueTag(issueID))
+	}
+
+	if err := resolver.Save(); err != nil {
+		log.Printf("Could not save issue cache: %s", err)
+	}
+
+	if activityID != "" {
+		tags = append(tags, "A_"+activityID)
+	}
+
+	if comment != "" {
+		tags = append(tags, commentTagFor(comment))
+	}
+
+	return tags, nil
+}
+
+func trackingFlags() []cli.Flag {
+	return append(append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "activity",
+			Usage: "The Redmine activity ID of the entry.",
+		},
+		&cli.BoolFlag{
+			Name:  "offline",
+			Usage: "Validate the issues against the local issue cache only.",
+		},
+	}, redmineFlags()...), jiraFlags()...)
+}
+
+func startCommand() cli.Command {
+	return cli.Command{
+		Name:      "start",
+		Usage:     "Validate the issues and start tracking them in timewarrior.",
+		ArgsUsage: "<issue>... [comment]",
+		Flags:     trackingFlags(),
+		Action: func(ctx *cli.Context) error {
+			aliases, err := aliasMap()
+			if err != nil {
+				return err
+			}
+
+			tags, err := trackingTags(ctx, aliases)
+			if err != nil {
+				return err
+			}
+
+			return timew(append([]string{"start"}, tags...)...)
+		},
+	}
+}
+
+func switchCommand() cli.Command {
+	return cli.Command{
+		Name:      "switch",
+		Usage:     "Stop the current interval and start tracking other issues.",
+		ArgsUsage: "<issue>... [comment]",
+		Flags:     trackingFlags(),
+		Action: func(ctx *cli.Context) error {
+			aliases, err := aliasMap()
+			if err != nil {
+				return err
+			}
+
+			// validate before stopping, so a typo does not end the interval
+			tags, err := trackingTags(ctx, aliases)
+			if err != nil {
+				return err
+			}
+
+			// `timew start` ends the running interval by itself
+			return timew(append([]string{"start"}, tags...)...)
+		},
+	}
+}
+
+func stopCommand() cli.Command {
+	return cli.Command{
+		Name:  "stop",
+		Usage: "Stop the current interval.",
+		Action: func(ctx *cli.Context) error {
+			return timew("stop")
+		},
+	}
+}
+
+func continueCommand() cli.Command {
+	return cli.Command{
+		Name:      "continue",
+		Usage:     "Continue the last interval, or the interval with the given ID.",
+		ArgsUsage: "[id]",
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() > 0 {
+				return timew("continue", "@"+strings.TrimPrefix(ctx.Args().First(), "@"))
+			}
+			return timew("continue")
+		},
+	}
+}
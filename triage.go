@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+const suggestionLimit = 8
+
+// Suggestion is an issue which likely fits an untagged entry.
+type Suggestion struct {
+	IssueID  string
+	Activity string
+	Reason   string
+	score    int
+	best     int
+	lastUsed time.Time
+}
+
+// triageTags are the tags of an entry which say something about its content,
+// i.e. without sync markers and activities.
+func triageTags(te TimeEntry) []string {
+	tags := []string{}
+	for _, tag := range te.Tags {
+		if tag == "S2R" || tag == "S2J" || strings.HasPrefix(tag, "S2R:") || strings.HasPrefix(tag, "S2J:") || strings.HasPrefix(tag, "A_") {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// suggest ranks the issues of the history for the entry. Issues booked with
+// the same comment rank first, followed by shared tags and recent use.
+func suggest(entry TimeEntry, history []TimeEntry) []Suggestion {
+	tags := triageTags(entry)
+	comment := strings.ToLower(strings.TrimSpace(entry.Comment))
+
+	suggestions := map[string]*Suggestion{}
+	for _, past := range history {
+		score := 0
+		reason := "recently used"
+		if comment != "" && strings.ToLower(strings.TrimSpace(past.Comment)) == comment {
+			score += 10
+			reason = "same comment"
+		}
+
+		shared := []string{}
+		for _, tag := range triageTags(past) {
+			for _, t := range tags {
+				if tag == t {
+					shared = append(shared, tag)
+				}
+			}
+		}
+		if len(shared) > 0 {
+			score += 2 * len(shared)
+			if reason != "same comment" {
+				reason = "tagged " + strings.Join(shared, ", ")
+			}
+		}
+
+		for _, issueID := range past.IssueIDs {
+			suggestion, ok := suggestions[issueID]
+			if !ok {
+				suggestion = &Suggestion{IssueID: issueID, Reason: reason}
+				suggestions[issueID] = suggestion
+			}
+
+			// the reason is taken from the best matching entry
+			if score > suggestion.best {
+				suggestion.best = score
+				suggestion.Reason = reason
+			}
+			suggestion.score += score + 1
+			if past.End.After(suggestion.lastUsed) {
+				suggestion.lastUsed = past.End
+				if past.ActivityID != "" {
+					suggestion.Activity = past.ActivityID
+				}
+			}
+		}
+	}
+
+	result := []Suggestion{}
+	for _, suggestion := range suggestions {
+		result = append(result, *suggestion)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].score != result[j].score {
+			return result[i].score > result[j].score
+		}
+		if !result[i].lastUsed.Equal(result[j].lastUsed) {
+			return result[i].lastUsed.After(result[j].lastUsed)
+		}
+		return result[i].IssueID < result[j].IssueID
+	})
+
+	if len(result) > suggestionLimit {
+		result = result[:suggestionLimit]
+	}
+	return result
+}
+
+// untagged returns the entries which are not booked on any issue, leaving
+// out absences. Parts of an interval are merged again, as the tags belong to
+// the interval.
+func (el *EntryList) untagged(absenceTags []string) []TimeEntry {
+	seen := map[string]bool{}
+	entries := []TimeEntry{}
+	for _, entry := range el.Entries {
+		if len(entry.IssueIDs) > 0 || seen[entry.ID] || entry.hasAnyTag(absenceTags) {
+			continue
+		}
+		seen[entry.ID] = true
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func writeSuggestions(suggestions []Suggestion, resolver *IssueResolver) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "Issue", "Subject", "Activity", "Reason"})
+	for index, suggestion := range suggestions {
+		info, _ := resolver.Resolve(suggestion.IssueID)
+		table.Append([]string{strconv.Itoa(index), suggestion.IssueID, info.Subject, suggestion.Activity, suggestion.Reason})
+	}
+	table.Render()
+}
+
+// triageEntry asks for the issue of the entry and tags it in timewarrior. It
+// returns false when the triage was quit.
+func triageEntry(entry TimeEntry, suggestions []Suggestion, resolver *IssueResolver, aliases map[string]Alias, activityID string, reader *bufio.Reader) (bool, error) {
+	loc := localTime()
+	fmt.Printf(
+		"\n@%s  %s - %s  %.2fh  %s  %s\n",
+		entry.ID,
+		entry.Start.In(loc).Format("Mon 2006-01-02 15:04"),
+		entry.End.In(loc).Format("15:04"),
+		entry.Hours.Hours(),
+		strings.Join(triageTags(entry), " "),
+		entry.Comment,
+	)
+	writeSuggestions(suggestions, resolver)
+
+	for {
+		fmt.Print("Number, issue or alias (empty to skip, q to quit): ")
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return false, err
+		}
+		input := strings.TrimSpace(line)
+
+		switch input {
+		case "":
+			return true, nil
+		case "q":
+			return false, nil
+		}
+
+		issueID, activity := "", activityID
+		if index, err := strconv.Atoi(input); err == nil && index >= 0 && index < len(suggestions) {
+			issueID = suggestions[index].IssueID
+			if activity == "" {
+				activity = suggestions[index].Activity
+			}
+		} else if alias, ok := aliases[input]; ok {
+			issueID = alias.Issue
+			if activity == "" {
+				activity = alias.Activity
+			}
+		} else {
+			issueID, err = normalizeIssue(input)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+		}
+
+		if _, err := validateIssue(resolver, issueID, activity); err != nil {
+			fmt.Println(err)
+			continue
+		}
+
+		tags := []string{issueTag(issueID)}
+		if activity != "" && strings.HasPrefix(issueID, "#") && entry.ActivityID == "" {
+			tags = append(tags, "A_"+activity)
+		}
+		if err := entry.retag(nil, tags); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+}
+
+func triageCommand() cli.Command {
+	return cli.Command{
+		Name:  "triage",
+		Usage: "Assign issues to the entries which are not booked on any issue.",
+		Flags: append(append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "range",
+				Value: "week",
+				Usage: "The time range to triage. Valid ranges are 'all', 'month', 'week', and 'day'.",
+			},
+			&cli.IntFlag{
+				Name:  "history",
+				Value: 90,
+				Usage: "The number of days of booked entries used for suggestions.",
+			},
+			&cli.BoolFlag{
+				Name:  "mine",
+				Usage: "Also suggest the open issues assigned to me.",
+			},
+			&cli.StringFlag{
+				Name:  "activity",
+				Usage: "The Redmine activity ID for the tagged entries.",
+			},
+			&cli.BoolFlag{
+				Name:  "offline",
+				Usage: "Use the local issue cache only.",
+			},
+		}, redmineFlags()...), jiraFlags()...),
+		Action: func(ctx *cli.Context) error {
+			aliases, err := aliasMap()
+			if err != nil {
+				return err
+			}
+
+			time_range := ctx.String("range")
+			if time_range != "all" && time_range != "month" && time_range != "week" && time_range != "day" {
+				log.Println("Invalid time range. Please use 'all', 'month', 'week', or 'day'.")
+				return nil
+			}
+
+			config, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			// the tags are changed on the intervals as stored in timewarrior
+			el := EntryList{Aliases: aliases, raw: true}
+			if err := el.fromTimeWarrior(time_range); err != nil {
+				return err
+			}
+			// entries covered by a rule are booked already
+			if err := el.applyRules(config.Rules); err != nil {
+				return err
+			}
+
+			entries := el.untagged(config.Balance.AbsenceTags)
+			if len(entries) == 0 {
+				fmt.Println("All entries are booked on issues.")
+				return nil
+			}
+
+			now := time.Now()
+			history := EntryList{Aliases: aliases, raw: true}
+			if err := history.fromOwnRange("triage", now.AddDate(0, 0, -ctx.Int("history")), now); err != nil {
+				return err
+			}
+
+			resolver, err := newIssueResolver(ctx)
+			if err != nil {
+				return err
+			}
+
+			assigned := []IssueInfo{}
+			if ctx.Bool("mine") && !resolver.Offline {
+				assigned, err = resolver.Search("", true)
+				if err != nil {
+					log.Printf("Could not load the assigned issues: %s", err)
+				}
+			}
+
+			commits := []Commit{}
+			if len(config.Git.Repositories) > 0 {
+				commits = config.Git.commits(span(entries))
+			}
+
+			reader := bufio.NewReader(os.Stdin)
+			for _, entry := range entries {
+				// issues referenced by the commits of the entry fit best
+				suggestions := commitSuggestions(entry, commits)
+				for _, suggestion := range suggest(entry, history.Entries) {
+					known := false
+					for _, s := range suggestions {
+						known = known || s.IssueID == suggestion.IssueID
+					}
+					if !known {
+						suggestions = append(suggestions, suggestion)
+					}
+				}
+				for _, issue := range assigned {
+					known := false
+					for _, suggestion := range suggestions {
+						known = known || suggestion.IssueID == issue.ID
+					}
+					if !known {
+						suggestions = append(suggestions, Suggestion{IssueID: issue.ID, Reason: "assigned"})
+					}
+				}
+
+				next, err := triageEntry(entry, suggestions, resolver, aliases, ctx.String("activity"), reader)
+				if err != nil {
+					return err
+				}
+				if !next {
+					break
+				}
+			}
+
+			return resolver.Save()
+		},
+	}
+}
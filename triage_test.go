@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestSuggest(t *testing.T) {
+	history := []TimeEntry{}
+	for _, entry := range []TimeWarriorEntry{
+		{ID: 4, Start: "20261001T080000Z", End: "20261001T090000Z", Tags: []string{"R_100", "A_9", "standup", "S2R"}},
+		{ID: 3, Start: "20261002T080000Z", End: "20261002T090000Z", Tags: []string{"R_200", "fixed the login"}},
+		{ID: 2, Start: "20261003T080000Z", End: "20261003T090000Z", Tags: []string{"J_5", "reviewed the release"}},
+		{ID: 1, Start: "20261004T080000Z", End: "20261004T090000Z", Tags: []string{"meeting"}},
+	} {
+		te, err := parse(entry, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err)
+		}
+		history = append(history, *te)
+	}
+
+	entry, _ := parse(TimeWarriorEntry{
+		ID:    0,
+		Start: "20261005T080000Z",
+		End:   "20261005T083000Z",
+		Tags:  []string{"standup", "Fixed the login"},
+	}, nil)
+
+	suggestions := suggest(*entry, history)
+	if len(suggestions) != 3 {
+		t.Fatalf("Expected 3 suggestions, got %d", len(suggestions))
+	}
+	if suggestions[0].IssueID != "#200" || suggestions[0].Reason != "same comment" {
+		t.Errorf("Expected #200 by comment first, got %s (%s)", suggestions[0].IssueID, suggestions[0].Reason)
+	}
+	if suggestions[1].IssueID != "#100" || suggestions[1].Activity != "9" || suggestions[1].Reason != "tagged standup" {
+		t.Errorf("Expected #100 with activity 9 by tag second, got %+v", suggestions[1])
+	}
+	if suggestions[2].IssueID != "PIM-5" || suggestions[2].Reason != "recently used" {
+		t.Errorf("Expected PIM-5 as recently used last, got %+v", suggestions[2])
+	}
+}
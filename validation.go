@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Check inspects the entries and returns the problems it found, keyed by
+// TimeEntry.key.
+type Check func(entries []TimeEntry) map[string][]string
+
+// key identifies the entry among the portions and parts of its interval,
+// which share the ID of the interval.
+func (te *TimeEntry) key() string {
+	return fmt.Sprintf("%s@%s/%s", te.ID, te.Start.UTC().Format("20060102T150405Z"), strings.Join(te.IssueIDs, ","))
+}
+
+// validate runs the checks and attaches the problems to the entries, which
+// makes them show up in the "Problems" column of `list`.
+func (el *EntryList) validate(checks ...Check) {
+	for _, check := range checks {
+		problems := check(el.Entries)
+		for i := range el.Entries {
+			el.Entries[i].errors = append(el.Entries[i].errors, problems[el.Entries[i].key()]...)
+		}
+	}
+}
+
+// entryCheck reports the problems of single entries which prevent syncing.
+// Empty comments are reported by commentCheck, as templates may fill them.
+func entryCheck(entries []TimeEntry) map[string][]string {
+	problems := map[string][]string{}
+	for _, entry := range entries {
+		if entry.IsJira && !entry.IsRedmine {
+			problems[entry.key()] = append(problems[entry.key()], "Jira entry without Redmine issue")
+		}
+
+		if entry.IsRedmine && entry.IsJira && len(entry.IssueIDs) < 2 {
+			problems[entry.key()] = append(problems[entry.key()], "Redmine and Jira issue without both issue IDs")
+		}
+
+		if entry.IsRedmine && entry.ActivityID == "" {
+			problems[entry.key()] = append(problems[entry.key()], "Redmine entry without activity ID")
+		}
+	}
+	return problems
+}
+
+// calendarCheck warns about entries on holidays and on days without target
+// hours on a weekend.
+func calendarCheck(wc *WorkCalendar) Check {
+	return func(entries []TimeEntry) map[string][]string {
+		problems := map[string][]string{}
+		loc := localTime()
+		for _, entry := range entries {
+			day := entry.Start.In(loc)
+			if name, ok := wc.Holidays.Holiday(day); ok {
+				problems[entry.key()] = append(problems[entry.key()], fmt.Sprintf("Logged on holiday %s", name))
+				continue
+			}
+
+			if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+				continue
+			}
+			target, err := wc.Target(day)
+			if err == nil && target == 0 {
+				problems[entry.key()] = append(problems[entry.key()], fmt.Sprintf("Logged on a %s", day.Weekday()))
+			}
+		}
+		return problems
+	}
+}
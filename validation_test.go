@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidatePortions(t *testing.T) {
+	loc := localTime()
+	// Friday 22:00 until Saturday 02:00
+	start := time.Date(2026, 10, 9, 22, 0, 0, 0, loc)
+	interval := TimeEntry{ID: "1", Start: start, End: start.Add(4 * time.Hour), Hours: 4 * time.Hour}
+
+	el := EntryList{Entries: interval.splitAtMidnight(loc)}
+	el.validate(calendarCheck(&WorkCalendar{}))
+
+	if len(el.Entries) != 2 {
+		t.Fatalf("expected 2 portions, got %d", len(el.Entries))
+	}
+	if len(el.Entries[0].errors) != 0 {
+		t.Errorf("expected no problems on Friday, got %q", el.Entries[0].errors)
+	}
+	if len(el.Entries[1].errors) != 1 || el.Entries[1].errors[0] != "Logged on a Saturday" {
+		t.Errorf("expected the weekend on Saturday, got %q", el.Entries[1].errors)
+	}
+}